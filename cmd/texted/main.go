@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -17,12 +18,17 @@ func main() {
 
 	// Add subcommands
 	rootCmd.AddCommand(commands.NewEditCommand())
+	rootCmd.AddCommand(commands.NewRunCommand())
 	rootCmd.AddCommand(commands.NewParseCommand())
 	rootCmd.AddCommand(commands.NewTestCommand())
 	rootCmd.AddCommand(commands.NewDocCommand())
 	rootCmd.AddCommand(commands.NewMCPCommand())
 
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr *commands.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		os.Exit(1)
 	}
 }
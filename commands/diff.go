@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dhamidi/texted/diff"
+	"github.com/dhamidi/texted/edlisp"
+)
+
+// ExitError makes texted exit with Code instead of the usual status 1.
+// Err is the error to report, or nil if there is nothing to report.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// diffReport prints the changes made by a script as unified diffs on stdout,
+// for --diff and --dry-run. It remembers whether any file changed so that the
+// exit status can tell.
+type diffReport struct {
+	context int
+	color   bool
+	changed bool
+}
+
+// newDiffReport creates a diffReport with context lines around each change.
+// The color setting is one of auto, always and never; auto colors the diff
+// if stdout is a terminal and NO_COLOR is not set.
+func newDiffReport(context int, color string) (*diffReport, error) {
+	if context < 0 {
+		return nil, fmt.Errorf("--context must not be negative")
+	}
+
+	report := &diffReport{context: context}
+	switch color {
+	case "always":
+		report.color = true
+	case "never":
+	case "auto":
+		info, err := os.Stdout.Stat()
+		report.color = err == nil && info.Mode()&os.ModeCharDevice != 0 && os.Getenv("NO_COLOR") == ""
+	default:
+		return nil, fmt.Errorf("invalid --color: %s (must be auto, always, or never)", color)
+	}
+	return report, nil
+}
+
+// print prints the diff from before to after for the file called name.
+// Files are labelled a/name and b/name like git does, so the output can be
+// applied with patch -p1; stdin keeps its name.
+func (d *diffReport) print(name, before, after string) {
+	clean := filepath.ToSlash(filepath.Clean(name))
+	oldName, newName := "a/"+clean, "b/"+clean
+	if name == "stdin" {
+		oldName, newName = name, name
+	}
+
+	// Show files in other encodings as text, unless the script converted them
+	if encoding := edlisp.DetectEncoding(before); encoding == edlisp.DetectEncoding(after) {
+		before, after = encoding.Decode(before), encoding.Decode(after)
+	}
+
+	text := diff.Unified(oldName, newName, before, after, d.context)
+	if text == "" {
+		return
+	}
+	d.changed = true
+	if d.color {
+		text = diff.Colorize(text)
+	}
+	os.Stdout.WriteString(text)
+}
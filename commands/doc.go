@@ -6,6 +6,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/dhamidi/texted"
 	"github.com/dhamidi/texted/edlisp"
 )
 
@@ -13,6 +14,7 @@ import (
 func NewDocCommand() *cobra.Command {
 	var category string
 	var verbose bool
+	var noPlugins bool
 
 	cmd := &cobra.Command{
 		Use:   "doc [function-name]",
@@ -21,27 +23,36 @@ func NewDocCommand() *cobra.Command {
 
 Without arguments, lists all available functions.
 With a function name, shows detailed documentation for that function.
-Use --category to filter by function category.`,
+Use --category to filter by function category.
+
+Plugins (texted-fn-* programs on PATH or listed in the plugin configuration
+file) are documented alongside the builtins unless --no-plugins is given.`,
 		Example: `  texted doc                    # List all functions
   texted doc search-forward     # Show docs for search-forward
   texted doc --category search  # Show all search functions
   texted doc --verbose          # Show detailed list with summaries`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runDoc(args, category, verbose)
+			return runDoc(args, category, verbose, noPlugins)
 		},
 	}
 
 	cmd.Flags().StringVar(&category, "category", "", "Filter functions by category")
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show function summaries in list view")
+	cmd.Flags().BoolVar(&noPlugins, "no-plugins", false, "Do not load texted-fn-* plugins")
 
 	return cmd
 }
 
 // runDoc handles the doc command execution.
-func runDoc(args []string, category string, verbose bool) error {
+func runDoc(args []string, category string, verbose bool, noPlugins bool) error {
+	env, err := texted.Options{Plugins: loadPlugins(noPlugins)}.NewEnvironment()
+	if err != nil {
+		return err
+	}
+
 	// If a specific function is requested
 	if len(args) == 1 {
-		return showFunctionDoc(args[0])
+		return showFunctionDoc(env, args[0])
 	}
 
 	// If too many arguments
@@ -50,12 +61,12 @@ func runDoc(args []string, category string, verbose bool) error {
 	}
 
 	// List functions
-	return listFunctions(category, verbose)
+	return listFunctions(env, category, verbose)
 }
 
 // showFunctionDoc displays detailed documentation for a specific function.
-func showFunctionDoc(functionName string) error {
-	doc, exists := edlisp.GetDocumentation(functionName)
+func showFunctionDoc(env *edlisp.Environment, functionName string) error {
+	doc, exists := env.Documentation(functionName)
 	if !exists {
 		return fmt.Errorf("no documentation found for function: %s", functionName)
 	}
@@ -117,16 +128,16 @@ func showFunctionDoc(functionName string) error {
 }
 
 // listFunctions shows a list of available functions, optionally filtered by category.
-func listFunctions(category string, verbose bool) error {
+func listFunctions(env *edlisp.Environment, category string, verbose bool) error {
 	var docs []edlisp.FunctionDoc
 
 	if category != "" {
-		docs = edlisp.GetDocumentationByCategory(category)
+		docs = env.DocumentationByCategory(category)
 		if len(docs) == 0 {
 			return fmt.Errorf("no functions found in category: %s", category)
 		}
 	} else {
-		docs = edlisp.GetAllDocumentation()
+		docs = env.AllDocumentation()
 	}
 
 	if len(docs) == 0 {
@@ -1,51 +1,92 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/dhamidi/texted"
+	"github.com/dhamidi/texted/diff"
 	"github.com/dhamidi/texted/edlisp"
-	"github.com/dhamidi/texted/edlisp/parser"
 	"github.com/dhamidi/texted/edlisp/writer"
 )
 
 // runEditArgs holds the arguments for the runEdit function
 type runEditArgs struct {
-	cmd          *cobra.Command
-	scriptFormat string
-	scriptFile   string
-	inPlace      bool
-	outputFile   string
-	backupSuffix string
-	verbose      bool
-	quiet        bool
-	dryRun       bool
-	shell        bool
-	sexp         bool
-	json         bool
-	outputFormat string
-	files        []string
+	cmd            *cobra.Command
+	script         string
+	expressions    []string
+	scriptFormat   string
+	scriptFile     string
+	inPlace        bool
+	outputFile     string
+	backupSuffix   string
+	symlinks       string
+	fsync          bool
+	eol            string
+	encoding       string
+	records        bool
+	nullData       bool
+	recordSep      string
+	atomic         bool
+	jobs           int
+	recursive      bool
+	include        []string
+	exclude        []string
+	noIgnore       bool
+	binary         bool
+	allowGenerated bool
+	filesFrom      string
+	null           bool
+	verbose        bool
+	quiet          bool
+	dryRun         bool
+	diff           bool
+	report         string
+	color          string
+	context        int
+	shell          bool
+	sexp           bool
+	json           bool
+	outputFormat   string
+	allowFS        string
+	allowCommands  []string
+	commandTimeout time.Duration
+	noPlugins      bool
+	loadPath       []string
+	scriptArgs     []string
+	each           string
+	files          []string
 }
 
 // runExpressionsArgs holds the arguments for the runExpressions function
 type runExpressionsArgs struct {
 	expressions  []string
 	scriptFormat string
+	options      texted.Options
 	outputFormat string
 	verbose      bool
 	quiet        bool
+	jobs         int
 	files        []string
 }
 
 // evaluateExpressionsOnContentArgs holds the arguments for the evaluateExpressionsOnContent function
 type evaluateExpressionsOnContentArgs struct {
 	expressions  []string
+	programs     [][]edlisp.Value
+	out          io.Writer
 	scriptFormat string
+	options      texted.Options
 	outputFormat string
 	verbose      bool
 	quiet        bool
@@ -55,43 +96,53 @@ type evaluateExpressionsOnContentArgs struct {
 
 // processStdinArgs holds the arguments for the processStdin function
 type processStdinArgs struct {
-	script       string
+	program      *texted.Program
 	scriptFormat string
+	options      texted.Options
 	outputFile   string
 	verbose      bool
 	quiet        bool
 	dryRun       bool
+	diff         *diffReport
 }
 
 // processFilesArgs holds the arguments for the processFiles function
 type processFilesArgs struct {
 	files        []string
-	script       string
+	program      *texted.Program
 	scriptFormat string
+	options      texted.Options
 	inPlace      bool
+	atomic       bool
+	jobs         int
 	outputFile   string
 	backupSuffix string
 	verbose      bool
 	quiet        bool
 	dryRun       bool
+	diff         *diffReport
+	report       *jsonReport
 }
 
 // processSingleFileToOutputArgs holds the arguments for the processSingleFileToOutput function
 type processSingleFileToOutputArgs struct {
 	filename     string
-	script       string
+	program      *texted.Program
 	scriptFormat string
+	options      texted.Options
 	outputFile   string
 	verbose      bool
 	quiet        bool
 	dryRun       bool
+	diff         *diffReport
 }
 
 // processSingleFileToStdoutArgs holds the arguments for the processSingleFileToStdout function
 type processSingleFileToStdoutArgs struct {
 	filename     string
-	script       string
+	program      *texted.Program
 	scriptFormat string
+	options      texted.Options
 	verbose      bool
 	quiet        bool
 	dryRun       bool
@@ -100,12 +151,17 @@ type processSingleFileToStdoutArgs struct {
 // processFilesInPlaceArgs holds the arguments for the processFilesInPlace function
 type processFilesInPlaceArgs struct {
 	files        []string
-	script       string
+	program      *texted.Program
 	scriptFormat string
+	options      texted.Options
+	atomic       bool
+	jobs         int
 	backupSuffix string
 	verbose      bool
 	quiet        bool
 	dryRun       bool
+	diff         *diffReport
+	report       *jsonReport
 }
 
 // NewEditCommand creates the edit subcommand.
@@ -113,17 +169,12 @@ func NewEditCommand() *cobra.Command {
 	var (
 		scriptFormat string
 		scriptFile   string
-		inPlace      bool
-		outputFile   string
-		backupSuffix string
-		verbose      bool
-		quiet        bool
-		dryRun       bool
 		shell        bool
 		sexp         bool
 		json         bool
 		outputFormat string
 	)
+	editArgs := &runEditArgs{}
 
 	cmd := &cobra.Command{
 		Use:   "edit [flags] [files...]",
@@ -138,22 +189,26 @@ Script formats:
   sexp:   S-expression syntax (e.g., "(search-forward \"hello\")")
   json:   JSON array syntax (e.g., ["search-forward", "hello"])`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runEdit(&runEditArgs{
-				cmd:          cmd,
-				scriptFormat: scriptFormat,
-				scriptFile:   scriptFile,
-				inPlace:      inPlace,
-				outputFile:   outputFile,
-				backupSuffix: backupSuffix,
-				verbose:      verbose,
-				quiet:        quiet,
-				dryRun:       dryRun,
-				shell:        shell,
-				sexp:         sexp,
-				json:         json,
-				outputFormat: outputFormat,
-				files:        args,
-			})
+			script, err := cmd.Flags().GetString("script")
+			if err != nil {
+				return fmt.Errorf("getting script flag: %w", err)
+			}
+			expressions, err := cmd.Flags().GetStringSlice("expression")
+			if err != nil {
+				return fmt.Errorf("getting expression flag: %w", err)
+			}
+
+			editArgs.cmd = cmd
+			editArgs.script = script
+			editArgs.expressions = expressions
+			editArgs.scriptFormat = scriptFormat
+			editArgs.scriptFile = scriptFile
+			editArgs.shell = shell
+			editArgs.sexp = sexp
+			editArgs.json = json
+			editArgs.outputFormat = outputFormat
+			editArgs.files = args
+			return runEdit(editArgs)
 		},
 	}
 
@@ -162,26 +217,62 @@ Script formats:
 	cmd.Flags().StringVarP(&scriptFile, "file", "f", "", "Read script from file")
 	cmd.Flags().StringSliceP("expression", "e", nil, "Execute single expression and print result (can be used multiple times)")
 
-	// Input/Output Options
-	cmd.Flags().BoolVarP(&inPlace, "in-place", "i", false, "Edit files in place (modify original files)")
-	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Write output to FILE (single file mode only)")
-	cmd.Flags().StringVar(&backupSuffix, "backup", "", "Create backup files with SUFFIX when using --in-place")
-
 	// Script Format Options
 	cmd.Flags().StringVar(&scriptFormat, "format", "shell", "Specify script format: shell, sexp, json")
 	cmd.Flags().BoolVar(&shell, "shell", false, "Force shell-like syntax parsing")
 	cmd.Flags().BoolVar(&sexp, "sexp", false, "Force S-expression syntax parsing")
 	cmd.Flags().BoolVar(&json, "json", false, "Force JSON syntax parsing")
-
-	// Behavior Options
-	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
-	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Suppress all output except errors")
-	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Show what would be done without making changes")
 	cmd.Flags().StringVar(&outputFormat, "output-format", "shell", "Output format for expression results: shell, sexp, json")
 
+	addFileFlags(cmd, editArgs)
+
 	return cmd
 }
 
+// addFileFlags adds the input/output and behavior flags shared by edit and run to cmd,
+// storing their values in args.
+func addFileFlags(cmd *cobra.Command, args *runEditArgs) {
+	// Input/Output Options
+	cmd.Flags().BoolVarP(&args.inPlace, "in-place", "i", false, "Edit files in place (modify original files)")
+	cmd.Flags().StringVarP(&args.outputFile, "output", "o", "", "Write output to FILE (single file mode only)")
+	cmd.Flags().StringVar(&args.backupSuffix, "backup", "", "Create backup files with SUFFIX when using --in-place")
+	cmd.Flags().StringVar(&args.symlinks, "symlinks", string(texted.FollowSymlinks), "Writing a file that is a symbolic link: follow (edit the target) or refuse")
+	cmd.Flags().BoolVar(&args.fsync, "fsync", false, "Flush written files to disk before reporting success")
+	cmd.Flags().StringVar(&args.eol, "eol", "", "Convert line endings to unix (\\n), dos (\\r\\n) or mac (\\r) instead of keeping them")
+	cmd.Flags().StringVar(&args.encoding, "encoding", "", "Read files as utf-8, utf-16le, utf-16be, iso-8859-1 or windows-1252 instead of detecting their encoding")
+	cmd.Flags().IntVarP(&args.jobs, "jobs", "j", runtime.GOMAXPROCS(0), "Process up to N files at the same time")
+	cmd.Flags().BoolVar(&args.atomic, "atomic", false, "Edit all files or none: write nothing unless the script succeeds on every file")
+	cmd.Flags().BoolVar(&args.records, "records", false, "Run the script on each line of the input separately and stream the results to stdout")
+	cmd.Flags().BoolVarP(&args.nullData, "null-data", "z", false, "With --records, records are separated by NUL characters instead of newlines")
+	cmd.Flags().StringVar(&args.recordSep, "record-separator", "", "With --records, records are separated by matches of REGEX instead of newlines")
+
+	// File Selection Options
+	cmd.Flags().BoolVarP(&args.recursive, "recursive", "r", false, "Edit the files below directories, skipping those ignored by .gitignore and .textedignore")
+	cmd.Flags().StringArrayVar(&args.include, "include", nil, "Only edit files matching GLOB (can be used multiple times)")
+	cmd.Flags().StringArrayVar(&args.exclude, "exclude", nil, "Do not edit files or enter directories matching GLOB (can be used multiple times)")
+	cmd.Flags().BoolVar(&args.noIgnore, "no-ignore", false, "Do not skip files ignored by .gitignore and .textedignore")
+	cmd.Flags().StringVar(&args.filesFrom, "files-from", "", "Also edit the files listed in FILE, one per line (- reads the list from stdin)")
+	cmd.Flags().BoolVarP(&args.null, "null", "0", false, "Names in --files-from are separated by NUL characters, as printed by find -print0")
+	cmd.Flags().BoolVar(&args.binary, "binary", false, "Edit files that look binary instead of skipping them")
+	cmd.Flags().BoolVar(&args.allowGenerated, "allow-generated", false, "Edit generated files, marked by headers such as \"DO NOT EDIT\", instead of skipping them")
+
+	// Behavior Options
+	cmd.Flags().BoolVarP(&args.verbose, "verbose", "v", false, "Enable verbose output")
+	cmd.Flags().BoolVarP(&args.quiet, "quiet", "q", false, "Suppress all output except errors")
+	cmd.Flags().BoolVarP(&args.dryRun, "dry-run", "n", false, "Run the script and show the changes as a unified diff without writing any file")
+	cmd.Flags().BoolVar(&args.diff, "diff", false, "Show the changes as a unified diff instead of the edited content, or in addition to editing with --in-place or --output")
+	cmd.Flags().StringVar(&args.report, "report", "text", "Report the outcome for every file as text, json or jsonl (JSON Lines) on stdout; json and jsonl need --in-place or --dry-run")
+	cmd.Flags().StringVar(&args.color, "color", "auto", "Color diffs: auto, always, never")
+	cmd.Flags().IntVarP(&args.context, "context", "U", diff.DefaultContext, "Show N lines of context around each change in diffs")
+	cmd.Flags().StringVar(&args.allowFS, "allow-fs", "", "Allow scripts to read and write files below DIR (disabled by default)")
+	cmd.Flags().StringArrayVar(&args.allowCommands, "allow-command", nil, "Allow scripts to run the program NAME through shell commands (can be used multiple times)")
+	cmd.Flags().DurationVar(&args.commandTimeout, "command-timeout", edlisp.DefaultCommandTimeout, "Maximum run time of a shell command")
+	cmd.Flags().BoolVar(&args.noPlugins, "no-plugins", false, "Do not load texted-fn-* plugins")
+	cmd.Flags().StringArrayVar(&args.loadPath, "load-path", nil, "Search DIR for scripts loaded with load (can be used multiple times)")
+	cmd.Flags().StringArrayVar(&args.scriptArgs, "arg", nil, "Set the script argument NAME used as $NAME to VALUE, given as NAME=VALUE (can be used multiple times)")
+	cmd.Flags().StringVar(&args.each, "each", "", "Run the script once at every match of REGEX, with point at the end of the match")
+}
+
 // runEdit handles the edit command execution.
 func runEdit(args *runEditArgs) error {
 	// Handle format shorthand flags
@@ -198,6 +289,33 @@ func runEdit(args *runEditArgs) error {
 		return fmt.Errorf("invalid script format: %s (must be shell, sexp, or json)", args.scriptFormat)
 	}
 
+	jsonOut, err := newJSONReport(args.report, os.Stdout)
+	if err != nil {
+		return err
+	}
+	if jsonOut != nil {
+		// Only the report goes to stdout
+		args.quiet, args.verbose = true, false
+	}
+
+	// Select the files to edit
+	files, selecting, err := selectFiles(args)
+	if err != nil {
+		return err
+	}
+	if selecting && len(files) == 0 {
+		if args.verbose && !args.quiet {
+			fmt.Println("No files selected")
+		}
+		if jsonOut != nil {
+			if err := jsonOut.finish(); err != nil {
+				return fmt.Errorf("writing report: %w", err)
+			}
+		}
+		return nil
+	}
+	args.files = files
+
 	// Validate flag combinations
 	if args.outputFile != "" && len(args.files) > 1 {
 		return fmt.Errorf("--output can only be used with a single file")
@@ -208,72 +326,204 @@ func runEdit(args *runEditArgs) error {
 	if args.outputFile != "" && args.inPlace {
 		return fmt.Errorf("--output and --in-place cannot be used together")
 	}
+	if args.jobs < 1 {
+		return fmt.Errorf("--jobs must be at least 1")
+	}
+	if args.atomic && !args.inPlace {
+		return fmt.Errorf("--atomic can only be used with --in-place")
+	}
+	if err := checkRecordFlags(args); err != nil {
+		return err
+	}
+	if err := checkReportFlags(args); err != nil {
+		return err
+	}
+	if !texted.IsValidSymlinkPolicy(args.symlinks) {
+		return fmt.Errorf("invalid --symlinks: %s (must be follow or refuse)", args.symlinks)
+	}
 
-	// Handle expressions
-	expressions, err := args.cmd.Flags().GetStringSlice("expression")
+	var eol edlisp.EOLType
+	if args.eol != "" {
+		if eol, err = edlisp.ParseEOLType(args.eol); err != nil {
+			return fmt.Errorf("invalid --eol: %w", err)
+		}
+	}
+	var encoding edlisp.Encoding
+	if args.encoding != "" {
+		if encoding, err = edlisp.ParseEncoding(args.encoding); err != nil {
+			return fmt.Errorf("invalid --encoding: %w", err)
+		}
+	}
+
+	options := texted.Options{
+		Format:          args.scriptFormat,
+		AllowedCommands: args.allowCommands,
+		CommandTimeout:  args.commandTimeout,
+		Output:          edlisp.WriterSink(os.Stderr),
+		Plugins:         loadPlugins(args.noPlugins),
+		LoadPath:        args.loadPath,
+		Each:            args.each,
+		EOL:             eol,
+		Encoding:        encoding,
+		Binary:          args.binary,
+		AllowGenerated:  args.allowGenerated,
+		Write: texted.WriteOptions{
+			Symlinks: texted.SymlinkPolicy(args.symlinks),
+			Sync:     args.fsync,
+		},
+	}
+	if _, err := regexp.Compile(args.each); err != nil {
+		return fmt.Errorf("invalid --each regexp: %w", err)
+	}
+	scriptArgs, err := parseScriptArgs(args.scriptArgs)
 	if err != nil {
-		return fmt.Errorf("getting expression flag: %w", err)
+		return err
+	}
+	options.Args = scriptArgs
+	// Script files can load the files next to them, inline scripts those in the current directory.
+	if args.scriptFile != "" {
+		options.LoadPath = append(options.LoadPath, filepath.Dir(args.scriptFile))
+	} else {
+		options.LoadPath = append(options.LoadPath, ".")
+	}
+	if args.allowFS != "" {
+		fsys, err := edlisp.DirFS(args.allowFS)
+		if err != nil {
+			return fmt.Errorf("opening --allow-fs directory: %w", err)
+		}
+		options.FileSystem = fsys
 	}
 
-	if len(expressions) > 0 {
+	// Handle expressions
+	if len(args.expressions) > 0 && args.each != "" {
+		return fmt.Errorf("--each cannot be used with --expression")
+	}
+	if len(args.expressions) > 0 {
 		return runExpressions(&runExpressionsArgs{
-			expressions:  expressions,
+			expressions:  args.expressions,
 			scriptFormat: args.scriptFormat,
+			options:      options,
 			outputFormat: args.outputFormat,
 			verbose:      args.verbose,
 			quiet:        args.quiet,
+			jobs:         args.jobs,
 			files:        args.files,
 		})
 	}
 
-	// Get script content
-	var script string
+	// Get script content, unless the caller has read it already
+	script := args.script
 
-	if args.scriptFile != "" {
+	if script == "" && args.scriptFile != "" {
 		content, err := os.ReadFile(args.scriptFile)
 		if err != nil {
 			return fmt.Errorf("reading script file: %w", err)
 		}
 		script = string(content)
-	} else {
-		// Get script from --script flag
-		script, err = args.cmd.Flags().GetString("script")
+	} else if script == "" {
+		return fmt.Errorf("either --script, --file, or --expression must be specified")
+	}
+
+	// Report syntax errors and missing arguments before touching any file.
+	// The parsed program is shared by all files.
+	program, err := options.Compile(script)
+	if err != nil {
+		return err
+	}
+
+	// --dry-run and --diff show the changes and report them in the exit status
+	var report *diffReport
+	if args.dryRun || args.diff {
+		report, err = newDiffReport(args.context, args.color)
 		if err != nil {
-			return fmt.Errorf("getting script flag: %w", err)
-		}
-		if script == "" {
-			return fmt.Errorf("either --script, --file, or --expression must be specified")
+			return err
 		}
 	}
 
-	// If no files specified, process stdin to stdout
+	if args.records {
+		return processRecords(&processRecordsArgs{
+			files:     args.files,
+			program:   program,
+			null:      args.nullData,
+			separator: args.recordSep,
+			verbose:   args.verbose && !args.quiet,
+		})
+	}
+
 	if len(args.files) == 0 {
-		return processStdin(&processStdinArgs{
-			script:       script,
+		// If no files specified, process stdin to stdout
+		err = processStdin(&processStdinArgs{
+			program:      program,
 			scriptFormat: args.scriptFormat,
+			options:      options,
 			outputFile:   args.outputFile,
 			verbose:      args.verbose,
 			quiet:        args.quiet,
 			dryRun:       args.dryRun,
+			diff:         report,
 		})
+	} else {
+		err = processFiles(&processFilesArgs{
+			files:        args.files,
+			program:      program,
+			scriptFormat: args.scriptFormat,
+			options:      options,
+			inPlace:      args.inPlace,
+			atomic:       args.atomic,
+			jobs:         args.jobs,
+			outputFile:   args.outputFile,
+			backupSuffix: args.backupSuffix,
+			verbose:      args.verbose,
+			quiet:        args.quiet,
+			dryRun:       args.dryRun,
+			diff:         report,
+			report:       jsonOut,
+		})
+	}
+	if jsonOut != nil {
+		if reportErr := jsonOut.finish(); reportErr != nil && err == nil {
+			err = fmt.Errorf("writing report: %w", reportErr)
+		}
+		// The report tells what went wrong, usage would not help
+		args.cmd.SilenceUsage = true
+	}
+	if errors.Is(err, errMatchesFailed) {
+		// The failed matches have been reported already
+		args.cmd.SilenceUsage = true
 	}
 
-	// Process files
-	return processFiles(&processFilesArgs{
-		files:        args.files,
-		script:       script,
-		scriptFormat: args.scriptFormat,
-		inPlace:      args.inPlace,
-		outputFile:   args.outputFile,
-		backupSuffix: args.backupSuffix,
-		verbose:      args.verbose,
-		quiet:        args.quiet,
-		dryRun:       args.dryRun,
-	})
+	if report == nil {
+		return err
+	}
+	// Like diff(1): 0 if nothing changes, 1 if something does, 2 on errors
+	if err != nil {
+		return &ExitError{Code: 2, Err: err}
+	}
+	if report.changed {
+		args.cmd.SilenceErrors = true
+		args.cmd.SilenceUsage = true
+		return &ExitError{Code: 1}
+	}
+	return nil
 }
 
-// runExpressions handles the --expression flag by evaluating expressions and printing results
+// runExpressions handles the --expression flag by evaluating expressions and printing results.
+// The expressions are parsed once and evaluated on up to args.jobs files at
+// the same time; the results are printed in the order of the files.
 func runExpressions(args *runExpressionsArgs) error {
+	// Parse the expressions and bind their arguments
+	programs := make([][]edlisp.Value, len(args.expressions))
+	for i, expr := range args.expressions {
+		program, err := args.options.Parse(expr)
+		if err != nil {
+			if !args.quiet {
+				fmt.Printf("Error parsing expression %d: %v\n", i+1, err)
+			}
+			return err
+		}
+		programs[i] = program
+	}
+
 	// If no files specified, read from stdin
 	if len(args.files) == 0 {
 		content, err := io.ReadAll(os.Stdin)
@@ -282,7 +532,10 @@ func runExpressions(args *runExpressionsArgs) error {
 		}
 		return evaluateExpressionsOnContent(&evaluateExpressionsOnContentArgs{
 			expressions:  args.expressions,
+			programs:     programs,
+			out:          os.Stdout,
 			scriptFormat: args.scriptFormat,
+			options:      args.options,
 			outputFormat: args.outputFormat,
 			verbose:      args.verbose,
 			quiet:        args.quiet,
@@ -291,71 +544,90 @@ func runExpressions(args *runExpressionsArgs) error {
 		})
 	}
 
-	// If multiple files, evaluate expressions on each file
-	for _, filename := range args.files {
-		content, err := os.ReadFile(filename)
-		if err != nil {
-			if !args.quiet {
-				fmt.Printf("Error reading %s: %v\n", filename, err)
-			}
-			return fmt.Errorf("reading %s: %w", filename, err)
-		}
+	// evaluation is what evaluating the expressions on one file printed
+	type evaluation struct {
+		readErr  error
+		out      strings.Builder
+		messages edlisp.CapturedOutput
+		err      error
+	}
 
-		if len(args.files) > 1 && !args.quiet {
-			fmt.Printf("=== %s ===\n", filename)
+	// If multiple files, evaluate expressions on each file, stopping at the first error
+	var stopped atomic.Bool
+	var firstErr error
+	texted.InOrder(len(args.files), args.jobs, func(i int) *evaluation {
+		e := &evaluation{}
+		if stopped.Load() {
+			return e
+		}
+		content, err := os.ReadFile(args.files[i])
+		if err != nil {
+			e.readErr = err
+			return e
 		}
 
-		err = evaluateExpressionsOnContent(&evaluateExpressionsOnContentArgs{
+		options := args.options
+		options.Output = &e.messages
+		e.err = evaluateExpressionsOnContent(&evaluateExpressionsOnContentArgs{
 			expressions:  args.expressions,
+			programs:     programs,
+			out:          &e.out,
 			scriptFormat: args.scriptFormat,
+			options:      options,
 			outputFormat: args.outputFormat,
 			verbose:      args.verbose,
 			quiet:        args.quiet,
 			content:      string(content),
-			source:       filename,
+			source:       args.files[i],
 		})
-		if err != nil {
-			return err
+		return e
+	}, func(i int, e *evaluation) {
+		if firstErr != nil {
+			return
 		}
-	}
-	return nil
+		filename := args.files[i]
+		if e.readErr != nil {
+			if !args.quiet {
+				fmt.Printf("Error reading %s: %v\n", filename, e.readErr)
+			}
+			firstErr = fmt.Errorf("reading %s: %w", filename, e.readErr)
+			stopped.Store(true)
+			return
+		}
+
+		if len(args.files) > 1 && !args.quiet {
+			fmt.Printf("=== %s ===\n", filename)
+		}
+		for _, text := range e.messages.Entries {
+			os.Stderr.WriteString(text)
+		}
+		os.Stdout.WriteString(e.out.String())
+		if e.err != nil {
+			firstErr = e.err
+			stopped.Store(true)
+		}
+	})
+	return firstErr
 }
 
 // evaluateExpressionsOnContent evaluates expressions on the given content
 func evaluateExpressionsOnContent(args *evaluateExpressionsOnContentArgs) error {
-	buffer := edlisp.NewBuffer(args.content)
-	env := edlisp.NewDefaultEnvironment()
+	buffer := args.options.NewBuffer(args.content)
+	env, err := args.options.NewEnvironment()
+	if err != nil {
+		return err
+	}
 
 	for i, expr := range args.expressions {
 		if args.verbose && !args.quiet {
-			fmt.Printf("Evaluating expression %d on %s: %s\n", i+1, args.source, expr)
-		}
-
-		// Parse the expression based on format
-		var program []edlisp.Value
-		var err error
-
-		switch args.scriptFormat {
-		case "shell", "sexp":
-			program, err = parser.ParseString(expr)
-		case "json":
-			program, err = parser.ParseJSONString(expr)
-		default:
-			return fmt.Errorf("unsupported script format: %s", args.scriptFormat)
-		}
-
-		if err != nil {
-			if !args.quiet {
-				fmt.Printf("Error parsing expression %d: %v\n", i+1, err)
-			}
-			return fmt.Errorf("parsing expression: %w", err)
+			fmt.Fprintf(args.out, "Evaluating expression %d on %s: %s\n", i+1, args.source, expr)
 		}
 
 		// Execute the expression and get the result value (not buffer content)
-		result, err := edlisp.Eval(program, env, buffer)
+		result, err := edlisp.Eval(args.programs[i], env, buffer)
 		if err != nil {
 			if !args.quiet {
-				fmt.Printf("Error in expression %d: %v\n", i+1, err)
+				fmt.Fprintf(args.out, "Error in expression %d: %v\n", i+1, err)
 			}
 			return err
 		}
@@ -393,7 +665,7 @@ func evaluateExpressionsOnContent(args *evaluateExpressionsOnContentArgs) error
 				return fmt.Errorf("writing result: %w", err)
 			}
 
-			fmt.Printf("%s\n", buf.String())
+			fmt.Fprintf(args.out, "%s\n", strings.TrimSuffix(buf.String(), "\n"))
 		}
 	}
 	return nil
@@ -410,27 +682,34 @@ func processStdin(args *processStdinArgs) error {
 		fmt.Printf("Processing stdin with script in %s format\n", args.scriptFormat)
 	}
 
-	if args.dryRun {
-		if !args.quiet {
-			fmt.Printf("Would process %d bytes from stdin\n", len(content))
-		}
-		return nil
-	}
-
-	result, err := texted.ExecuteScriptWithFormat(string(content), args.script, args.scriptFormat)
+	result, matches, err := executeScript("stdin", string(content), args.program, args.quiet)
 	if err != nil {
 		return err
 	}
+	// The edits at the other matches are kept, so failed ones are reported last
+	failed := matchesError("stdin", matches)
+
+	if args.diff != nil {
+		args.diff.print("stdin", string(content), result)
+		if args.dryRun || args.outputFile == "" {
+			return failed
+		}
+	}
 
 	if args.outputFile != "" {
 		if args.verbose && !args.quiet {
 			fmt.Printf("Writing output to %s\n", args.outputFile)
 		}
-		return os.WriteFile(args.outputFile, []byte(result), 0644)
+		if err := texted.WriteFile(args.outputFile, []byte(result), args.options.Write); err != nil {
+			return err
+		}
+		return failed
 	}
 
-	_, err = os.Stdout.WriteString(result)
-	return err
+	if _, err := os.Stdout.WriteString(result); err != nil {
+		return err
+	}
+	return failed
 }
 
 // processFiles handles processing one or more files
@@ -439,12 +718,30 @@ func processFiles(args *processFilesArgs) error {
 		// Single file with output redirection
 		return processSingleFileToOutput(&processSingleFileToOutputArgs{
 			filename:     args.files[0],
-			script:       args.script,
+			program:      args.program,
 			scriptFormat: args.scriptFormat,
+			options:      args.options,
 			outputFile:   args.outputFile,
 			verbose:      args.verbose,
 			quiet:        args.quiet,
 			dryRun:       args.dryRun,
+			diff:         args.diff,
+		})
+	}
+
+	if !args.inPlace && args.outputFile == "" && args.diff != nil {
+		// Diffs replace the edited content, so no file is written
+		return processFilesInPlace(&processFilesInPlaceArgs{
+			files:        args.files,
+			program:      args.program,
+			scriptFormat: args.scriptFormat,
+			options:      args.options,
+			verbose:      args.verbose,
+			quiet:        args.quiet,
+			jobs:         args.jobs,
+			dryRun:       true,
+			diff:         args.diff,
+			report:       args.report,
 		})
 	}
 
@@ -453,8 +750,9 @@ func processFiles(args *processFilesArgs) error {
 		if len(args.files) == 1 {
 			return processSingleFileToStdout(&processSingleFileToStdoutArgs{
 				filename:     args.files[0],
-				script:       args.script,
+				program:      args.program,
 				scriptFormat: args.scriptFormat,
+				options:      args.options,
 				verbose:      args.verbose,
 				quiet:        args.quiet,
 				dryRun:       args.dryRun,
@@ -466,12 +764,17 @@ func processFiles(args *processFilesArgs) error {
 	// In-place editing
 	return processFilesInPlace(&processFilesInPlaceArgs{
 		files:        args.files,
-		script:       args.script,
+		program:      args.program,
 		scriptFormat: args.scriptFormat,
+		options:      args.options,
+		atomic:       args.atomic,
+		jobs:         args.jobs,
 		backupSuffix: args.backupSuffix,
 		verbose:      args.verbose,
 		quiet:        args.quiet,
 		dryRun:       args.dryRun,
+		diff:         args.diff,
+		report:       args.report,
 	})
 }
 
@@ -481,24 +784,28 @@ func processSingleFileToOutput(args *processSingleFileToOutputArgs) error {
 		fmt.Printf("Processing %s -> %s\n", args.filename, args.outputFile)
 	}
 
-	if args.dryRun {
-		if !args.quiet {
-			fmt.Printf("Would process %s and write to %s\n", args.filename, args.outputFile)
-		}
-		return nil
-	}
-
 	content, err := os.ReadFile(args.filename)
 	if err != nil {
 		return fmt.Errorf("reading %s: %w", args.filename, err)
 	}
+	if err := args.program.CheckContent(string(content)); err != nil {
+		return skipError(args.filename, err)
+	}
 
-	result, err := texted.ExecuteScriptWithFormat(string(content), args.script, args.scriptFormat)
+	result, matches, err := executeScript(args.filename, string(content), args.program, args.quiet)
 	if err != nil {
 		return fmt.Errorf("processing %s: %w", args.filename, err)
 	}
 
-	return os.WriteFile(args.outputFile, []byte(result), 0644)
+	if args.diff != nil {
+		args.diff.print(args.filename, string(content), result)
+	}
+	if !args.dryRun {
+		if err := texted.WriteFile(args.outputFile, []byte(result), args.options.Write); err != nil {
+			return err
+		}
+	}
+	return matchesError(args.filename, matches)
 }
 
 // processSingleFileToStdout processes a single file and writes to stdout
@@ -507,106 +814,220 @@ func processSingleFileToStdout(args *processSingleFileToStdoutArgs) error {
 		fmt.Printf("Processing %s -> stdout\n", args.filename)
 	}
 
-	if args.dryRun {
-		if !args.quiet {
-			fmt.Printf("Would process %s and write to stdout\n", args.filename)
-		}
-		return nil
-	}
-
 	content, err := os.ReadFile(args.filename)
 	if err != nil {
 		return fmt.Errorf("reading %s: %w", args.filename, err)
 	}
+	if err := args.program.CheckContent(string(content)); err != nil {
+		return skipError(args.filename, err)
+	}
 
-	result, err := texted.ExecuteScriptWithFormat(string(content), args.script, args.scriptFormat)
+	result, matches, err := executeScript(args.filename, string(content), args.program, args.quiet)
 	if err != nil {
 		return fmt.Errorf("processing %s: %w", args.filename, err)
 	}
 
-	_, err = os.Stdout.WriteString(result)
-	return err
+	if _, err := os.Stdout.WriteString(result); err != nil {
+		return err
+	}
+	return matchesError(args.filename, matches)
 }
 
-// processFilesInPlace processes files in place with optional backup
+// processFilesInPlace processes files in place with optional backup.
+// Up to args.jobs files are processed at the same time, but everything is
+// reported in the order of the files. The files are edited by
+// texted.Options.EditFiles; this only reports what happened to them.
 func processFilesInPlace(args *processFilesInPlaceArgs) error {
-	hasErrors := false
-	for _, filename := range args.files {
-		if args.verbose && !args.quiet {
-			fmt.Printf("Processing %s in place\n", filename)
+	options := args.options
+	options.Jobs = args.jobs
+	options.Atomic = args.atomic
+	options.Backup = args.backupSuffix
+	options.DryRun = args.dryRun
+	allOrNothing := options.Atomic && !options.DryRun
+
+	var failed *texted.FileResult
+	matchesFailed := false
+	options.EditFiles(args.program, args.files, func(file *texted.FileResult) {
+		if file.Run != nil {
+			matchesFailed = matchesFailed || matchesError(file.Filename, file.Run.Matches) != nil
 		}
-
-		if args.dryRun {
-			if !args.quiet {
-				fmt.Printf("Would edit %s in place", filename)
-				if args.backupSuffix != "" {
-					fmt.Printf(" (backup to %s%s)", filename, args.backupSuffix)
-				}
-				fmt.Println()
-			}
-			continue
+		// Files that could not be restored are failed because of the file that could not be written
+		if failed == nil && file.Err != nil && file.Err != texted.ErrAborted && file.Stage != texted.StageRestore {
+			failed = file
 		}
-
-		// Create backup if requested
-		if args.backupSuffix != "" {
-			backupFile := filename + args.backupSuffix
-			if args.verbose && !args.quiet {
-				fmt.Printf("Creating backup %s\n", backupFile)
-			}
-
-			content, err := os.ReadFile(filename)
-			if err != nil {
-				if !args.quiet {
-					fmt.Printf("✗ Failed to read %s for backup: %v\n", filename, err)
-				}
-				hasErrors = true
-				continue
-			}
-
-			if err := os.WriteFile(backupFile, content, 0644); err != nil {
-				if !args.quiet {
-					fmt.Printf("✗ Failed to create backup %s: %v\n", backupFile, err)
-				}
-				hasErrors = true
-				continue
+		if args.report != nil {
+			// Script messages still go to stderr, everything else is in the report
+			reportFileOutput(file, true)
+			args.report.add(fileJSONReport(file))
+			if args.diff != nil && file.Run != nil && file.Run.Output != file.Original {
+				args.diff.changed = true
 			}
+			return
 		}
-
-		// Process the file
-		content, err := os.ReadFile(filename)
-		if err != nil {
-			if !args.quiet {
-				fmt.Printf("✗ Failed to read %s: %v\n", filename, err)
+		if args.verbose && !args.quiet {
+			fmt.Printf("Processing %s in place\n", file.Filename)
+			if file.BackedUp {
+				fmt.Printf("Creating backup %s%s\n", file.Filename, args.backupSuffix)
 			}
-			hasErrors = true
-			continue
 		}
-
-		result, err := texted.ExecuteScriptWithFormat(string(content), args.script, args.scriptFormat)
-		if err != nil {
-			if !args.quiet {
-				fmt.Printf("✗ Failed to process %s: %v\n", filename, err)
-			}
-			hasErrors = true
-			continue
+		if file.Skipped != nil {
+			reportSkipped(file, args.quiet, args.diff != nil)
+			return
 		}
-
-		if err := os.WriteFile(filename, []byte(result), 0644); err != nil {
+		reportFileOutput(file, args.quiet)
+		if file.Err == texted.ErrAborted {
+			return
+		}
+		if file.Err != nil {
 			if !args.quiet {
-				fmt.Printf("✗ Failed to write %s: %v\n", filename, err)
+				fmt.Printf("✗ %s\n", fileFailure(file, args.backupSuffix))
 			}
-			hasErrors = true
-			continue
+			return
 		}
 
-		if !args.quiet {
-			fmt.Printf("✓ Successfully edited %s\n", filename)
+		if args.diff != nil {
+			args.diff.print(file.Filename, file.Original, file.Run.Output)
+		} else if !args.quiet && !args.dryRun {
+			fmt.Printf("✓ Successfully edited %s\n", file.Filename)
 		}
-	}
+	})
 
-	if hasErrors {
+	switch {
+	case failed == nil:
+	case !allOrNothing:
 		return fmt.Errorf("some files could not be edited")
+	case failed.Stage == texted.StageBackup:
+		return fmt.Errorf("no files were edited: creating backup of %s: %w", failed.Filename, failed.Err)
+	case failed.Stage == texted.StageWrite:
+		var commitErr *texted.CommitError
+		if errors.As(failed.Err, &commitErr) && len(commitErr.Unrestored) > 0 {
+			return commitErr
+		}
+		return fmt.Errorf("no files were edited: %w", failed.Err)
+	default:
+		return fmt.Errorf("no files were edited because some could not be processed")
+	}
+	if matchesFailed {
+		return errMatchesFailed
 	}
 
 	return nil
 }
+
+// fileError returns the error that made file fail, without the files of an
+// atomic edit that CommitEdits reports along with it.
+func fileError(file *texted.FileResult) error {
+	var commitErr *texted.CommitError
+	if errors.As(file.Err, &commitErr) {
+		return commitErr.Err
+	}
+	return file.Err
+}
+
+// fileFailure describes why file could not be edited.
+func fileFailure(file *texted.FileResult, backupSuffix string) string {
+	err := fileError(file)
+	switch file.Stage {
+	case texted.StageRead:
+		return fmt.Sprintf("Failed to read %s: %v", file.Filename, err)
+	case texted.StageBackup:
+		return fmt.Sprintf("Failed to create backup %s%s: %v", file.Filename, backupSuffix, err)
+	case texted.StageWrite:
+		return fmt.Sprintf("Failed to write %s: %v", file.Filename, err)
+	case texted.StageRestore:
+		return fmt.Sprintf("Failed to restore %s: %v", file.Filename, err)
+	}
+	return fmt.Sprintf("Failed to process %s: %v", file.Filename, err)
+}
+
+// reportFileOutput prints what the script printed for the file and, with
+// --each, its match counts on stderr.
+func reportFileOutput(file *texted.FileResult, quiet bool) {
+	for _, text := range file.Output.Entries {
+		os.Stderr.WriteString(text)
+	}
+	if file.Run != nil && file.Run.Matches != nil {
+		reportMatches(file.Filename, *file.Run.Matches, quiet)
+	}
+}
+
+// reportSkipped tells that the file was not edited because of its content,
+// on stderr while diffs are printed on stdout.
+func reportSkipped(file *texted.FileResult, quiet, diff bool) {
+	if quiet {
+		return
+	}
+	out := os.Stdout
+	if diff {
+		out = os.Stderr
+	}
+	fmt.Fprintf(out, "- Skipped %s: %v\n", file.Filename, skipHint(file.Skipped))
+}
+
+// skipError is the error for a single file that is not edited because of its content.
+func skipError(filename string, reason error) error {
+	return fmt.Errorf("not editing %s: %v", filename, skipHint(reason))
+}
+
+// skipHint explains reason, texted.ErrBinary or texted.ErrGenerated, with
+// the flag that overrides it.
+func skipHint(reason error) string {
+	switch reason {
+	case texted.ErrBinary:
+		return "binary file (use --binary to edit it)"
+	case texted.ErrGenerated:
+		return "generated file (use --allow-generated to edit it)"
+	}
+	return reason.Error()
+}
+
+// executeScript runs program on content, which was read from the file called name.
+// With --each, the match counts are reported on stderr, as are the errors at
+// failed matches, whose edits have been undone; they are also returned so
+// that failed matches can be reported by matchesError once the result is saved.
+func executeScript(name, content string, program *texted.Program, quiet bool) (string, *edlisp.EachResult, error) {
+	result, matches, err := program.Run(content)
+	if err != nil {
+		return "", nil, err
+	}
+	if matches != nil {
+		reportMatches(name, *matches, quiet)
+	}
+	return result, matches, nil
+}
+
+// errMatchesFailed tells that --each edited some files only partly.
+var errMatchesFailed = errors.New("the script failed at some matches")
+
+// matchesError returns an error if the script failed at any of the matches
+// in the file called name, and nil otherwise.
+func matchesError(name string, matches *edlisp.EachResult) error {
+	if matches == nil || matches.Failed == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s: %w (%d of %d failed)", name, errMatchesFailed, matches.Failed, matches.Processed+matches.Failed)
+}
+
+// reportMatches prints the match counts of --each unless quiet,
+// followed by the errors at failed matches.
+func reportMatches(name string, matches edlisp.EachResult, quiet bool) {
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", name, matches)
+	}
+	for _, err := range matches.Errors {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+	}
+}
+
+// parseScriptArgs parses the NAME=VALUE pairs given with --arg.
+func parseScriptArgs(pairs []string) (map[string]string, error) {
+	args := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --arg %q (must be NAME=VALUE)", pair)
+		}
+		args[name] = value
+	}
+	return args, nil
+}
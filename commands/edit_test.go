@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dhamidi/texted"
+)
+
+func TestProcessFilesInPlace_FailedMatches(t *testing.T) {
+	dir := t.TempDir()
+	var names []string
+	for _, name := range []string{"a.txt", "b.txt"} {
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, []byte("a1 a2\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, filename)
+	}
+
+	// The script fails at the second match of each file
+	program, err := texted.Options{Each: `a[12]`}.Compile(`replace-match "b"; search-forward " "`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, atomic := range []bool{false, true} {
+		err := processFilesInPlace(&processFilesInPlaceArgs{files: names, program: program, atomic: atomic, jobs: 1, quiet: true})
+		if !errors.Is(err, errMatchesFailed) {
+			t.Errorf("atomic=%v: processFilesInPlace() error = %v, want errMatchesFailed", atomic, err)
+		}
+	}
+	if content, _ := os.ReadFile(names[0]); string(content) != "b a2\n" {
+		t.Errorf("a.txt = %q, want the successful match edited", content)
+	}
+}
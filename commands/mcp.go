@@ -2,16 +2,26 @@ package commands
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/mark3labs/mcp-go/server"
 
+	"github.com/dhamidi/texted"
+	"github.com/dhamidi/texted/edlisp"
 	"github.com/dhamidi/texted/tools"
 )
 
 func NewMCPCommand() *cobra.Command {
 	var prefix string
+	var allowFS string
+	var allowCommands []string
+	var commandTimeout time.Duration
+	var plugins bool
+	var loadPath []string
+	var symlinks string
+	var fsync bool
 
 	cmd := &cobra.Command{
 		Use:   "mcp",
@@ -25,18 +35,57 @@ through standardized tools. The server communicates over stdio and provides:
 
 The server supports all texted script formats: shell-like syntax, S-expressions, and JSON.
 
-Use the --prefix flag to add a custom prefix to all tool names when registering them.`,
+Use the --prefix flag to add a custom prefix to all tool names when registering them.
+
+Scripts cannot access files other than the ones being edited unless --allow-fs
+names a directory; file builtins such as insert-file-contents are then confined to it.
+Likewise, shell-command-on-region only runs programs named with --allow-command,
+and load only reads scripts from the directories named with --load-path.
+
+Plugins (texted-fn-* programs on PATH or listed in the plugin configuration
+file) run with the rights of the server, so they are only available to
+scripts if --plugins is given.
+
+Edited files are replaced atomically and keep their permissions and owner.
+--symlinks refuse stops edit_file from writing through symbolic links.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runMCPServer(prefix)
+			if !texted.IsValidSymlinkPolicy(symlinks) {
+				return fmt.Errorf("invalid --symlinks: %s (must be follow or refuse)", symlinks)
+			}
+			return runMCPServer(prefix, tools.Options{
+				AllowedCommands: allowCommands,
+				CommandTimeout:  commandTimeout,
+				Plugins:         loadPlugins(!plugins),
+				LoadPath:        loadPath,
+				Write: texted.WriteOptions{
+					Symlinks: texted.SymlinkPolicy(symlinks),
+					Sync:     fsync,
+				},
+			}, allowFS)
 		},
 	}
 
 	cmd.Flags().StringVar(&prefix, "prefix", "", "Prefix to add to tool names")
+	cmd.Flags().StringVar(&allowFS, "allow-fs", "", "Allow scripts to read and write files below DIR (disabled by default)")
+	cmd.Flags().StringArrayVar(&allowCommands, "allow-command", nil, "Allow scripts to run the program NAME through shell commands (can be used multiple times)")
+	cmd.Flags().DurationVar(&commandTimeout, "command-timeout", edlisp.DefaultCommandTimeout, "Maximum run time of a shell command")
+	cmd.Flags().BoolVar(&plugins, "plugins", false, "Load texted-fn-* plugins (disabled by default)")
+	cmd.Flags().StringArrayVar(&loadPath, "load-path", nil, "Search DIR for scripts loaded with load (can be used multiple times, disabled by default)")
+	cmd.Flags().StringVar(&symlinks, "symlinks", string(texted.FollowSymlinks), "Writing a file that is a symbolic link: follow (edit the target) or refuse")
+	cmd.Flags().BoolVar(&fsync, "fsync", false, "Flush edited files to disk before reporting success")
 
 	return cmd
 }
 
-func runMCPServer(prefix string) error {
+func runMCPServer(prefix string, options tools.Options, allowFS string) error {
+	if allowFS != "" {
+		fsys, err := edlisp.DirFS(allowFS)
+		if err != nil {
+			return fmt.Errorf("opening --allow-fs directory: %w", err)
+		}
+		options.FileSystem = fsys
+	}
+
 	s := server.NewMCPServer(
 		"Texted MCP Server",
 		"1.0.0",
@@ -44,13 +93,13 @@ func runMCPServer(prefix string) error {
 	)
 
 	editFileTool := tools.NewEditFileToolWithPrefix(prefix)
-	s.AddTool(editFileTool, tools.EditFileHandler)
+	s.AddTool(editFileTool, options.EditFileHandler)
 
 	textedEvalTool := tools.NewTextedEvalToolWithPrefix(prefix)
-	s.AddTool(textedEvalTool, tools.TextedEvalHandler)
+	s.AddTool(textedEvalTool, options.TextedEvalHandler)
 
 	textedDocTool := tools.NewTextedDocToolWithPrefix(prefix)
-	s.AddTool(textedDocTool, tools.TextedDocHandler)
+	s.AddTool(textedDocTool, options.TextedDocHandler)
 
 	if err := server.ServeStdio(s); err != nil {
 		return fmt.Errorf("MCP server error: %w", err)
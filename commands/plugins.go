@@ -0,0 +1,21 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dhamidi/texted/edlisp"
+)
+
+// loadPlugins discovers the texted-fn-* plugins unless disabled.
+// Plugins that cannot be used are reported on stderr and skipped.
+func loadPlugins(disabled bool) []*edlisp.Plugin {
+	if disabled {
+		return nil
+	}
+	plugins, errs := edlisp.DiscoverPlugins()
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	return plugins
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/dhamidi/texted"
+)
+
+// processRecordsArgs holds the arguments for the processRecords function
+type processRecordsArgs struct {
+	files     []string
+	program   *texted.Program
+	null      bool
+	separator string
+	verbose   bool
+}
+
+// checkRecordFlags reports flags that cannot be combined with --records,
+// which streams its results to stdout instead of editing files.
+func checkRecordFlags(args *runEditArgs) error {
+	if !args.records {
+		if args.nullData || args.recordSep != "" {
+			return fmt.Errorf("--null-data and --record-separator can only be used with --records")
+		}
+		return nil
+	}
+
+	switch {
+	case args.nullData && args.recordSep != "":
+		return fmt.Errorf("--null-data and --record-separator cannot be used together")
+	case args.inPlace, args.outputFile != "":
+		return fmt.Errorf("--records writes to stdout and cannot be used with --in-place or --output")
+	case args.dryRun, args.diff:
+		return fmt.Errorf("--records cannot be used with --dry-run or --diff")
+	case len(args.expressions) > 0:
+		return fmt.Errorf("--records cannot be used with --expression")
+	}
+	return nil
+}
+
+// processRecords runs the program on every record of the files, or of stdin
+// if there are none, and streams the results to stdout.
+func processRecords(args *processRecordsArgs) error {
+	split := texted.RecordSplit{Null: args.null}
+	if args.separator != "" {
+		re, err := regexp.Compile(args.separator)
+		if err != nil {
+			return fmt.Errorf("invalid --record-separator regexp: %w", err)
+		}
+		split.Separator = re
+	}
+
+	if len(args.files) == 0 {
+		if err := args.program.RunRecords(os.Stdin, os.Stdout, split); err != nil {
+			return fmt.Errorf("processing stdin: %w", err)
+		}
+		return nil
+	}
+
+	for _, filename := range args.files {
+		if args.verbose {
+			fmt.Fprintf(os.Stderr, "Processing %s as records\n", filename)
+		}
+		f, err := os.Open(filename)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", filename, err)
+		}
+		err = args.program.RunRecords(f, os.Stdout, split)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("processing %s: %w", filename, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/dhamidi/texted"
+	"github.com/dhamidi/texted/diff"
+	"github.com/dhamidi/texted/edlisp"
+	"github.com/dhamidi/texted/edlisp/writer"
+)
+
+// The statuses of a file in a JSON report.
+const (
+	// statusOK means the script ran; the file may or may not have changed.
+	statusOK = "ok"
+	// statusSkipped means the file was not edited because of its content.
+	statusSkipped = "skipped"
+	// statusFailed means the file could not be read, edited or written.
+	statusFailed = "failed"
+	// statusAborted means --atomic left the file alone because another file failed.
+	statusAborted = "aborted"
+)
+
+// fileReport is the entry for one file in --report json and jsonl.
+type fileReport struct {
+	// Type is "file" in JSON Lines, where it tells entries from the summary.
+	Type string `json:"type,omitempty"`
+	File string `json:"file"`
+
+	Status string `json:"status"`
+	// Reason says why a file was skipped: binary or generated.
+	Reason string `json:"reason,omitempty"`
+
+	// Changed is set if the script changed the content, whether or not
+	// it was written.
+	Changed bool `json:"changed"`
+	Written bool `json:"written"`
+	// BytesChanged is the number of bytes the script changed; text between
+	// two changes does not count.
+	BytesChanged int `json:"bytes_changed"`
+
+	// Point and Mark are their final positions, or those at the failure.
+	Point int `json:"point,omitempty"`
+	Mark  int `json:"mark,omitempty"`
+	// Value is the value of the last expression of the script.
+	Value json.RawMessage `json:"value,omitempty"`
+
+	Matches *matchReport `json:"matches,omitempty"`
+	Error   *errorReport `json:"error,omitempty"`
+}
+
+// matchReport holds the match counts of --each.
+type matchReport struct {
+	Processed int      `json:"processed"`
+	Skipped   int      `json:"skipped"`
+	Failed    int      `json:"failed"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// errorReport describes why a file failed.
+type errorReport struct {
+	// Kind is the kind of a script error as used by condition-case, such
+	// as search-failed or assertion-failed, or one of read, backup, write
+	// and restore for file errors.
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	// Line and Column locate point in the file when the script failed.
+	Line   int `json:"line,omitempty"`
+	Column int `json:"column,omitempty"`
+}
+
+// reportSummary counts the files of a report by outcome.
+type reportSummary struct {
+	Type      string `json:"type,omitempty"`
+	Files     int    `json:"files"`
+	Changed   int    `json:"changed"`
+	Unchanged int    `json:"unchanged"`
+	Skipped   int    `json:"skipped"`
+	Failed    int    `json:"failed"`
+	Aborted   int    `json:"aborted"`
+}
+
+// jsonReport prints the outcome of editing files as JSON for --report.
+// JSON Lines prints each file as soon as it is done and the summary last;
+// JSON prints a single document with all files once they are done.
+type jsonReport struct {
+	lines   bool
+	out     io.Writer
+	files   []fileReport
+	summary reportSummary
+	// err is the first error writing the report, after which nothing
+	// more is written.
+	err error
+}
+
+// newJSONReport creates the report for the --report format, or returns nil
+// for the text format.
+func newJSONReport(format string, out io.Writer) (*jsonReport, error) {
+	switch format {
+	case "text":
+		return nil, nil
+	case "json":
+		return &jsonReport{out: out}, nil
+	case "jsonl":
+		return &jsonReport{lines: true, out: out}, nil
+	default:
+		return nil, fmt.Errorf("invalid --report: %s (must be text, json, or jsonl)", format)
+	}
+}
+
+// checkReportFlags reports flags that cannot be combined with a JSON
+// --report, which describes files edited in place or with --dry-run.
+func checkReportFlags(args *runEditArgs) error {
+	if args.report == "text" {
+		return nil
+	}
+
+	switch {
+	case len(args.expressions) > 0:
+		return fmt.Errorf("--report %s cannot be used with --expression", args.report)
+	case args.records:
+		return fmt.Errorf("--report %s cannot be used with --records", args.report)
+	case args.diff, args.outputFile != "":
+		return fmt.Errorf("--report %s prints to stdout and cannot be used with --diff or --output", args.report)
+	case len(args.files) == 0:
+		return fmt.Errorf("--report %s needs files to edit", args.report)
+	case !args.inPlace && !args.dryRun:
+		return fmt.Errorf("--report %s can only be used with --in-place or --dry-run", args.report)
+	}
+	return nil
+}
+
+// add records the report of a file.
+func (r *jsonReport) add(file fileReport) {
+	r.summary.Files++
+	switch {
+	case file.Status == statusSkipped:
+		r.summary.Skipped++
+	case file.Status == statusFailed:
+		r.summary.Failed++
+	case file.Status == statusAborted:
+		r.summary.Aborted++
+	case file.Changed:
+		r.summary.Changed++
+	default:
+		r.summary.Unchanged++
+	}
+
+	if r.lines {
+		file.Type = "file"
+		r.write(file)
+		return
+	}
+	r.files = append(r.files, file)
+}
+
+// finish prints the summary, and with JSON the files. It returns the
+// first error writing the report.
+func (r *jsonReport) finish() error {
+	if r.lines {
+		summary := r.summary
+		summary.Type = "summary"
+		r.write(summary)
+		return r.err
+	}
+	files := r.files
+	if files == nil {
+		files = []fileReport{}
+	}
+	r.write(struct {
+		Files   []fileReport  `json:"files"`
+		Summary reportSummary `json:"summary"`
+	}{files, r.summary})
+	return r.err
+}
+
+// write prints v as a line of JSON, unless writing the report failed before.
+func (r *jsonReport) write(v any) {
+	if r.err != nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		r.err = err
+		return
+	}
+	_, r.err = r.out.Write(append(data, '\n'))
+}
+
+// fileJSONReport returns the entry of an edited file in a JSON report.
+func fileJSONReport(r *texted.FileResult) fileReport {
+	file := fileReport{File: r.Filename, Status: statusOK, Written: r.Written}
+	switch {
+	case r.Skipped != nil:
+		file.Status = statusSkipped
+		file.Reason = "binary"
+		if r.Skipped == texted.ErrGenerated {
+			file.Reason = "generated"
+		}
+		return file
+	case r.Err == texted.ErrAborted:
+		file.Status = statusAborted
+	case r.Err != nil:
+		file.Status = statusFailed
+		kind := r.Stage
+		if kind == texted.StageScript {
+			kind = ""
+		}
+		file.Error = newErrorReport(kind, fileError(r))
+	}
+
+	if r.Run != nil {
+		file.Changed = r.Run.Output != r.Original
+		file.BytesChanged = diff.BytesChanged(r.Original, r.Run.Output)
+		file.Point, file.Mark = r.Run.Point, r.Run.Mark
+		file.Value = encodeValue(r.Run.Value)
+		if m := r.Run.Matches; m != nil {
+			file.Matches = &matchReport{Processed: m.Processed, Skipped: m.Skipped, Failed: m.Failed}
+			for _, err := range m.Errors {
+				file.Matches.Errors = append(file.Matches.Errors, err.Error())
+			}
+		}
+	}
+	var execErr *edlisp.ExecutionError
+	if errors.As(r.Err, &execErr) {
+		file.Point, file.Mark = execErr.Point, execErr.Mark
+	}
+	return file
+}
+
+// newErrorReport describes err, whose kind is kind, or the kind of script
+// error err is if kind is empty.
+func newErrorReport(kind string, err error) *errorReport {
+	report := &errorReport{Kind: kind, Message: err.Error()}
+	var execErr *edlisp.ExecutionError
+	if errors.As(err, &execErr) {
+		report.Message = execErr.OriginalError.Error()
+		report.Line, report.Column = execErr.Position()
+	}
+	if report.Kind == "" {
+		report.Kind = edlisp.ErrorKind(err)
+	}
+	return report
+}
+
+// encodeValue returns value as JSON, or nil if there is none.
+func encodeValue(value edlisp.Value) json.RawMessage {
+	if value == nil {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := (&writer.JSONWriter{}).WriteValue(&buf, value); err != nil {
+		return nil
+	}
+	return json.RawMessage(bytes.TrimSpace(buf.Bytes()))
+}
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dhamidi/texted"
+)
+
+func TestJSONReport(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.txt": "hello world\n",
+		"b.txt": "nothing\nto see\n",
+		"c.go":  "// Code generated by hand. DO NOT EDIT.\n",
+	}
+	var names []string
+	for _, name := range []string{"a.txt", "b.txt", "c.go"} {
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(files[name]), 0644); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, filename)
+	}
+
+	program, err := texted.Options{}.Compile(`end-of-line; search-backward "world"; replace-match "there"; point`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	report, _ := newJSONReport("jsonl", &out)
+	err = processFilesInPlace(&processFilesInPlaceArgs{files: names, program: program, jobs: 2, quiet: true, report: report})
+	if err == nil {
+		t.Error("processFilesInPlace() should fail for b.txt")
+	}
+	if err := report.finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []fileReport
+	var summary reportSummary
+	for line := range strings.Lines(out.String()) {
+		if strings.HasPrefix(line, `{"type":"summary"`) {
+			if err := json.Unmarshal([]byte(line), &summary); err != nil {
+				t.Fatalf("invalid summary %q: %v", line, err)
+			}
+			continue
+		}
+		var entry fileReport
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d file entries, want 3:\n%s", len(entries), out.String())
+	}
+
+	a, b, c := entries[0], entries[1], entries[2]
+	if a.Status != statusOK || !a.Changed || !a.Written || a.BytesChanged != 5 || string(a.Value) != "12" {
+		t.Errorf("a.txt entry = %+v", a)
+	}
+	if b.Status != statusFailed || b.Error == nil || b.Error.Kind != "search-failed" || b.Error.Line != 1 || b.Error.Column != 8 {
+		t.Errorf("b.txt entry = %+v, error = %+v", b, b.Error)
+	}
+	if c.Status != statusSkipped || c.Reason != "generated" {
+		t.Errorf("c.go entry = %+v", c)
+	}
+	want := reportSummary{Type: "summary", Files: 3, Changed: 1, Skipped: 1, Failed: 1}
+	if summary != want {
+		t.Errorf("summary = %+v, want %+v", summary, want)
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestJSONReportWriteError(t *testing.T) {
+	report, _ := newJSONReport("jsonl", failingWriter{})
+	report.add(fileReport{File: "a.txt", Status: statusOK})
+	if err := report.finish(); err == nil || err.Error() != "disk full" {
+		t.Errorf("finish() = %v, want disk full", err)
+	}
+}
@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dhamidi/texted/edlisp"
+	"github.com/dhamidi/texted/edlisp/parser"
+)
+
+// scriptHeader holds the defaults a script file declares in its header comment.
+type scriptHeader struct {
+	inPlace      bool
+	backupSuffix string
+	args         []string
+}
+
+// NewRunCommand creates the run subcommand.
+func NewRunCommand() *cobra.Command {
+	runArgs := &runEditArgs{}
+
+	cmd := &cobra.Command{
+		Use:   "run SCRIPT [flags] [files...]",
+		Short: "Run a texted script file",
+		Long: `Run a texted script file on files or on stdin, like texted edit -f SCRIPT.
+
+The script format is chosen by the extension of SCRIPT: .elsh for shell-like
+syntax, .el for S-expressions and .json for JSON. A first line starting with #!
+is skipped, so scripts can be made executable with
+
+  #!/usr/bin/env -S texted run
+
+Comments start with # or, in lines starting with ( or ;, with ;.
+The comment lines at the top of the script may declare defaults in lines
+starting with "texted:" (# texted: in .elsh and ; texted: in .el scripts). They accept --in-place, --backup and --arg; flags
+given on the command line take precedence:
+
+  # texted: --in-place --backup .orig --arg YEAR=2025`,
+		Example: `  texted run fix-headers.elsh -i src/*.go
+  ./fix-headers.elsh --arg YEAR=2026 src/main.go`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runArgs.cmd = cmd
+			runArgs.files = args[1:]
+			return runScriptFile(args[0], runArgs)
+		},
+	}
+
+	addFileFlags(cmd, runArgs)
+
+	return cmd
+}
+
+// runScriptFile runs the script file at path as configured by args and its header.
+func runScriptFile(path string, args *runEditArgs) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading script file: %w", err)
+	}
+
+	format := parser.FormatForFile(path)
+	script, header, err := parseScriptHeader(string(content), format)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if !args.cmd.Flags().Changed("in-place") {
+		args.inPlace = args.inPlace || header.inPlace
+	}
+	// The backup suffix only makes sense if the files end up being edited in place.
+	if !args.cmd.Flags().Changed("backup") && header.backupSuffix != "" && args.inPlace {
+		args.backupSuffix = header.backupSuffix
+	}
+	// Later values win, so arguments from the command line override the header.
+	args.scriptArgs = append(header.args, args.scriptArgs...)
+
+	args.scriptFormat = format
+	args.scriptFile = path
+	args.script = script
+	return runEdit(args)
+}
+
+// parseScriptHeader returns the defaults declared in the header of source,
+// the comment lines before the first expression, and source without the
+// shebang line, which JSON cannot skip. The line is left empty so that line
+// numbers stay the same; the parser skips all other comments.
+func parseScriptHeader(source, format string) (string, scriptHeader, error) {
+	var header scriptHeader
+	comment := map[string]string{"shell": "#", "sexp": ";"}[format]
+
+	lines := strings.SplitAfter(source, "\n")
+	for i, line := range lines {
+		text := strings.TrimSpace(line)
+		switch {
+		case i == 0 && strings.HasPrefix(text, "#!"):
+			lines[i] = strings.Repeat("\n", strings.Count(line, "\n"))
+		case text == "":
+		case comment != "" && strings.HasPrefix(text, comment):
+			options, ok := strings.CutPrefix(strings.TrimSpace(strings.TrimLeft(text, comment)), "texted:")
+			if ok {
+				if err := header.parse(options); err != nil {
+					return "", header, fmt.Errorf("line %d: %w", i+1, err)
+				}
+			}
+		default:
+			return strings.Join(lines, ""), header, nil
+		}
+	}
+
+	return strings.Join(lines, ""), header, nil
+}
+
+// parse records the options of a "texted:" header line.
+func (h *scriptHeader) parse(options string) error {
+	words, err := edlisp.SplitCommand(options)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(words); i++ {
+		name, value, hasValue := strings.Cut(words[i], "=")
+		switch name {
+		case "-i", "--in-place":
+			h.inPlace = true
+			continue
+		case "--backup", "--arg":
+		default:
+			return fmt.Errorf("unsupported option %q in script header (must be --in-place, --backup or --arg)", words[i])
+		}
+
+		if !hasValue {
+			if i+1 >= len(words) {
+				return fmt.Errorf("option %s in script header needs a value", name)
+			}
+			i++
+			value = words[i]
+		}
+		if name == "--backup" {
+			h.backupSuffix = value
+		} else {
+			h.args = append(h.args, value)
+		}
+	}
+
+	return nil
+}
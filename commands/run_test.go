@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseScriptHeader(t *testing.T) {
+	source := `#!/usr/bin/env -S texted run
+# Fix the copyright year.
+# texted: -i --backup=.orig --arg "MESSAGE=hello world"
+
+search-forward "Copyright"
+  # a comment
+# texted: --backup .bak
+`
+	script, header, err := parseScriptHeader(source, "shell")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if script != source[strings.Index(source, "\n"):] {
+		t.Errorf("expected only the shebang line to be removed, got %q", script)
+	}
+	if !header.inPlace || header.backupSuffix != ".orig" || strings.Join(header.args, "|") != "MESSAGE=hello world" {
+		t.Errorf("unexpected header %+v", header)
+	}
+
+	if _, _, err := parseScriptHeader("; texted: --allow-fs /\n(insert \"x\")\n", "sexp"); err == nil {
+		t.Error("expected an error for an unsupported header option")
+	}
+
+	script, _, err = parseScriptHeader("#!/usr/bin/env -S texted run\n[[\"insert\", \"x\"]]\n", "json")
+	if err != nil || script != "\n[[\"insert\", \"x\"]]\n" {
+		t.Errorf("expected only the shebang line to be removed, got %q (%v)", script, err)
+	}
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dhamidi/texted"
+)
+
+// selectFiles returns the files named on the command line and with
+// --files-from, filtered and expanded as configured by --recursive,
+// --include, --exclude and --no-ignore. The boolean reports whether any of
+// these options was given, in which case an empty result means there is
+// nothing to edit rather than that stdin should be edited.
+// A file named more than once is only returned the first time.
+func selectFiles(args *runEditArgs) ([]string, bool, error) {
+	paths := args.files
+	if args.filesFrom != "" {
+		list, err := readFileList(args.filesFrom, args.null)
+		if err != nil {
+			return nil, false, err
+		}
+		paths = append(paths, list...)
+	} else if args.null {
+		return nil, false, fmt.Errorf("--null can only be used with --files-from")
+	}
+
+	selecting := args.recursive || args.filesFrom != "" || len(args.include) > 0 || len(args.exclude) > 0
+	if !selecting {
+		return texted.UniqueFiles(paths), false, nil
+	}
+	if args.recursive && len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	files, err := texted.FileSelection{
+		Recursive: args.recursive,
+		Include:   args.include,
+		Exclude:   args.exclude,
+		NoIgnore:  args.noIgnore,
+	}.Select(paths)
+	return files, true, err
+}
+
+// readFileList reads the file names listed in the file called name, or on stdin if name is "-".
+// Names are separated by newlines, or by NUL characters if null is set.
+func readFileList(name string, null bool) ([]string, error) {
+	var content []byte
+	var err error
+	if name == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading file list: %w", err)
+	}
+
+	separator := "\n"
+	if null {
+		separator = "\x00"
+	}
+
+	var files []string
+	for _, entry := range strings.Split(string(content), separator) {
+		if !null {
+			entry = strings.TrimSuffix(entry, "\r")
+		}
+		if entry != "" {
+			files = append(files, entry)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,125 @@
+package texted
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dhamidi/texted/edlisp"
+)
+
+var (
+	// ErrBinary is the reason files that look binary are not edited.
+	ErrBinary = errors.New("binary file")
+
+	// ErrGenerated is the reason generated files are not edited.
+	ErrGenerated = errors.New("generated file")
+)
+
+// binarySample is how many bytes at the start of a file IsBinary looks at,
+// the same as git.
+const binarySample = 8000
+
+// generatedHeaderLines is how many lines at the start of a file IsGenerated looks at.
+const generatedHeaderLines = 20
+
+// generatedMarker matches the comments that mark generated files, such as
+// "Code generated by stringer; DO NOT EDIT.", "@generated" or
+// "This file was automatically generated".
+var generatedMarker = regexp.MustCompile(`(?i)\bdo not edit\b|@generated\b|\b(?:auto-?|automatically )generated (?:by|from|file|code)\b|\b(?:this|the) (?:file|code) (?:is|was|has been) (?:auto-?|automatically )?generated\b`)
+
+// IsBinary reports whether content looks like the content of a binary file
+// rather than text in any of the encodings texted reads. Content is binary
+// if its start contains a NUL byte, unless it is UTF-16 text, or if more
+// than 30% of it are control characters other than common whitespace and
+// escape characters. Bytes that are not valid UTF-8 count as well if the
+// content is read as UTF-8. In ISO-8859-1 and Windows-1252 every byte is a
+// character, but as all content that is not UTF-8 is detected as one of
+// them, the bytes 0x80 to 0xBF count unless the encoding is chosen: they
+// are symbols, or undefined, which text only uses now and then, while
+// accented letters are above them.
+func IsBinary(content string) bool {
+	return isBinary(content, "")
+}
+
+// isBinary is like IsBinary for content in encoding, or in the encoding
+// detected by edlisp.DetectEncoding if encoding is empty.
+func isBinary(content string, encoding edlisp.Encoding) bool {
+	sample := content[:min(len(content), binarySample)]
+	detected := encoding == ""
+	if detected {
+		encoding = edlisp.DetectEncoding(sample)
+	}
+	switch encoding {
+	case edlisp.EncodingUTF16LE, edlisp.EncodingUTF16BE:
+		return false
+	}
+	if strings.IndexByte(sample, 0) >= 0 {
+		return true
+	}
+
+	// In ISO-8859-1 and Windows-1252 every byte is a character
+	utf8Text := encoding == edlisp.EncodingUTF8
+	odd := 0
+	for i := 0; i < len(sample); {
+		r, size := utf8.DecodeRuneInString(sample[i:])
+		if !utf8Text {
+			r, size = rune(sample[i]), 1
+		}
+		switch {
+		case r == utf8.RuneError && size == 1,
+			r < 0x20 && !strings.ContainsRune("\t\n\v\f\r\b\x1b", r),
+			!utf8Text && detected && r >= 0x80 && r < 0xc0:
+			odd++
+		}
+		i += size
+	}
+	return odd*10 > len(sample)*3
+}
+
+// IsGenerated reports whether content has a header marking it as generated
+// by a tool, which will overwrite any edits. Only the first lines are
+// searched for markers like "DO NOT EDIT" and "@generated".
+func IsGenerated(content string) bool {
+	header := content
+	for i, n := 0, 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			if n++; n == generatedHeaderLines {
+				header = content[:i]
+				break
+			}
+		}
+	}
+	return generatedMarker.MatchString(header)
+}
+
+// CheckContent reports whether a file with content may be edited as
+// configured by o. It returns ErrBinary for binary files unless o.Binary is
+// set, ErrGenerated for generated files unless o.AllowGenerated is set, and
+// nil otherwise. Content is read in o.Encoding if it is set.
+func (o Options) CheckContent(content string) error {
+	if !o.Binary && isBinary(content, o.Encoding) {
+		return ErrBinary
+	}
+	if !o.AllowGenerated && IsGenerated(content) {
+		return ErrGenerated
+	}
+	return nil
+}
+
+// CheckContent is like Options.CheckContent for the options the program was compiled with.
+func (p *Program) CheckContent(content string) error {
+	return p.opts.CheckContent(content)
+}
+
+// skipReason returns ErrBinary or ErrGenerated if err says a file was not
+// edited because of its content, and nil otherwise.
+func skipReason(err error) error {
+	for _, reason := range []error{ErrBinary, ErrGenerated} {
+		if errors.Is(err, reason) {
+			return reason
+		}
+	}
+	return nil
+}
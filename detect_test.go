@@ -0,0 +1,128 @@
+package texted
+
+import (
+	"errors"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dhamidi/texted/edlisp"
+)
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{name: "empty", content: "", want: false},
+		{name: "utf-8", content: "Grüße, world\n\tindented\r\n", want: false},
+		{name: "iso-8859-1", content: "name=Jos\xe9\ncity=K\xf6ln\n", want: false},
+		{name: "short iso-8859-1", content: "\xe9\xe8\n", want: false},
+		{name: "windows-1252", content: "caf\xe9 \x93q\x94\n", want: false},
+		{name: "utf-16", content: "\xff\xfeh\x00i\x00\n\x00", want: false},
+		{name: "nul bytes", content: "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR", want: true},
+		{name: "mostly control characters", content: strings.Repeat("\x8f\xfe\x01\x02\x03", 100), want: true},
+		{name: "random bytes without nul", content: randomText(20000), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsBinary(tt.content); got != tt.want {
+				t.Errorf("IsBinary(%q) = %v, want %v", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+// randomText returns n random bytes other than NUL, which are detected as
+// Windows-1252 like any other content that is not UTF-8.
+func randomText(n int) string {
+	r := rand.New(rand.NewPCG(1, 2))
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(1 + r.IntN(255))
+	}
+	return string(b)
+}
+
+func TestIsGenerated(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{name: "go", content: "// Code generated by stringer -type=Kind; DO NOT EDIT.\n\npackage x\n", want: true},
+		{name: "go after license", content: "// Copyright 2025\n\n//go:build linux\n\n// Code generated by mkerrors.sh; DO NOT EDIT.\n", want: true},
+		{name: "generated tag", content: "/**\n * @generated SignedSource<<abc>>\n */\n", want: true},
+		{name: "prose", content: "# This file was automatically generated from schema.json\n", want: true},
+		{name: "hand written", content: "package x\n\n// generated reports are stored in out/\n", want: false},
+		{name: "marker below the header", content: strings.Repeat("line\n", 30) + "// DO NOT EDIT\n", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsGenerated(tt.content); got != tt.want {
+				t.Errorf("IsGenerated(%q) = %v, want %v", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditFilesWithOptions_SkipsBinaryAndGeneratedFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"text.txt":  "text\n",
+		"image.png": "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR",
+		"gen.go":    "// Code generated by hand. DO NOT EDIT.\n",
+	}
+	var names []string
+	for _, name := range []string{"text.txt", "image.png", "gen.go"} {
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(files[name]), 0644); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, filename)
+	}
+
+	for _, atomic := range []bool{false, true} {
+		results, err := EditFilesWithOptions(names, `insert ">"`, Options{Atomic: atomic})
+		if err != nil {
+			t.Fatalf("EditFilesWithOptions() error = %v", err)
+		}
+		if !results[0].Success {
+			t.Errorf("atomic=%v: text.txt was not edited: %+v", atomic, results[0])
+		}
+		if results[1].Skipped != ErrBinary || results[1].Error != nil {
+			t.Errorf("atomic=%v: image.png result = %+v, want skipped as binary", atomic, results[1])
+		}
+		if results[2].Skipped != ErrGenerated || results[2].Error != nil {
+			t.Errorf("atomic=%v: gen.go result = %+v, want skipped as generated", atomic, results[2])
+		}
+	}
+	for _, name := range []string{"image.png", "gen.go"} {
+		if content, _ := os.ReadFile(filepath.Join(dir, name)); string(content) != files[name] {
+			t.Errorf("%s was changed to %q", name, content)
+		}
+	}
+
+	if err := (Options{Encoding: edlisp.EncodingWindows1252}).CheckContent("caf\xe9 \x93q\x94\n"); err != nil {
+		t.Errorf("CheckContent() of windows-1252 text error = %v", err)
+	}
+	if err := (Options{Encoding: edlisp.EncodingLatin1}).CheckContent(randomText(20000)); err != nil {
+		t.Errorf("CheckContent() of random bytes read as iso-8859-1 error = %v", err)
+	}
+	if err := (Options{Encoding: edlisp.EncodingUTF8}).CheckContent(strings.Repeat("\xe9\xe8", 10)); err != ErrBinary {
+		t.Errorf("CheckContent() of invalid utf-8 read as utf-8 error = %v, want ErrBinary", err)
+	}
+
+	err := EditFileWithOptions(names[1], `insert ">"`, Options{})
+	if !errors.Is(err, ErrBinary) {
+		t.Errorf("EditFileWithOptions() error = %v, want ErrBinary", err)
+	}
+	if err := EditFileWithOptions(names[2], `insert ">"`, Options{AllowGenerated: true}); err != nil {
+		t.Errorf("EditFileWithOptions() with AllowGenerated error = %v", err)
+	}
+}
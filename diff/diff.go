@@ -0,0 +1,356 @@
+// Package diff computes line-based unified diffs between two versions of a text.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContext is the number of unchanged lines shown around each change,
+// as in diff -u.
+const DefaultContext = 3
+
+// op is one line of an edit script. Kind is ' ' for a line both texts
+// share, '-' for a line only in the old text and '+' for a line only in
+// the new text. Old and New are the 0-based line numbers the op is at.
+type op struct {
+	kind byte
+	old  int
+	new  int
+	line string
+}
+
+// Unified returns a unified diff that turns oldText into newText.
+// The headers name the texts oldName and newName, and each hunk shows up to
+// context unchanged lines around its changes. Unified returns "" if the texts are equal.
+func Unified(oldName, newName, oldText, newText string, context int) string {
+	if oldText == newText {
+		return ""
+	}
+	if context < 0 {
+		context = 0
+	}
+
+	ops := edits(splitLines(oldText), splitLines(newText))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+
+	for i := 0; i < len(ops); {
+		for i < len(ops) && ops[i].kind == ' ' {
+			i++
+		}
+		if i == len(ops) {
+			break
+		}
+
+		// Start with the context before the change, but not before the end of the previous hunk.
+		start := i
+		for start > 0 && i-start < context && ops[start-1].kind == ' ' {
+			start--
+		}
+
+		// Extend the hunk over all changes whose context overlaps.
+		end := i
+		for {
+			for end < len(ops) && ops[end].kind != ' ' {
+				end++
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next < len(ops) && next-end <= 2*context {
+				end = next
+				continue
+			}
+			end = min(end+context, next)
+			break
+		}
+
+		writeHunk(&out, ops[start:end])
+		i = end
+	}
+
+	return out.String()
+}
+
+// BytesChanged returns the number of bytes that differ between oldText and
+// newText. Each run of changed lines in the edit script counts the text
+// between its first and last difference, in the old or the new lines,
+// whichever is longer, so the unchanged text between two changes does not count.
+func BytesChanged(oldText, newText string) int {
+	if oldText == newText {
+		return 0
+	}
+
+	total := 0
+	var removed, added strings.Builder
+	for _, o := range append(edits(splitLines(oldText), splitLines(newText)), op{kind: ' '}) {
+		switch o.kind {
+		case '-':
+			removed.WriteString(o.line)
+		case '+':
+			added.WriteString(o.line)
+		default:
+			total += spanChanged(removed.String(), added.String())
+			removed.Reset()
+			added.Reset()
+		}
+	}
+	return total
+}
+
+// spanChanged returns the length of the text between the first and the last
+// difference of before and after, in whichever is longer.
+func spanChanged(before, after string) int {
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+	return max(len(before), len(after)) - prefix - suffix
+}
+
+// writeHunk writes the header and lines of a hunk.
+func writeHunk(out *strings.Builder, hunk []op) {
+	oldCount, newCount := 0, 0
+	for _, o := range hunk {
+		if o.kind != '+' {
+			oldCount++
+		}
+		if o.kind != '-' {
+			newCount++
+		}
+	}
+
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(hunk[0].old, oldCount), hunkRange(hunk[0].new, newCount))
+	for _, o := range hunk {
+		out.WriteByte(o.kind)
+		out.WriteString(o.line)
+		if !strings.HasSuffix(o.line, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the range of a hunk starting at the 0-based line start.
+// Like GNU diff, an empty range names the line before it and a count of 1 is omitted.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
+
+// splitLines splits text into lines that keep their line endings.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// edits returns the shortest edit script turning a into b, using the
+// algorithm from Myers' "An O(ND) Difference Algorithm and Its Variations".
+func edits(a, b []string) []op {
+	// Lines shared at both ends are not part of any change.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []op
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, op{kind: ' ', old: i, new: i, line: a[i]})
+	}
+	for _, o := range middleEdits(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		o.old += prefix
+		o.new += prefix
+		ops = append(ops, o)
+	}
+	for i := suffix; i > 0; i-- {
+		ops = append(ops, op{kind: ' ', old: len(a) - i, new: len(b) - i, line: a[len(a)-i]})
+	}
+	return ops
+}
+
+// middleEdits returns the edit script turning a into b using the linear
+// space variant of Myers' algorithm: it finds the middle snake of a shortest
+// edit path and recurses on the lines before and after it, so memory stays
+// proportional to the number of lines however many of them change.
+func middleEdits(a, b []string) []op {
+	var ops []op
+	var walk func(x0, x1, y0, y1 int)
+	walk = func(x0, x1, y0, y1 int) {
+		for x0 < x1 && y0 < y1 && a[x0] == b[y0] {
+			ops = append(ops, op{kind: ' ', old: x0, new: y0, line: a[x0]})
+			x0++
+			y0++
+		}
+		suffix := 0
+		for x1-suffix > x0 && y1-suffix > y0 && a[x1-1-suffix] == b[y1-1-suffix] {
+			suffix++
+		}
+		x1, y1 = x1-suffix, y1-suffix
+
+		switch {
+		case x0 == x1:
+			for y := y0; y < y1; y++ {
+				ops = append(ops, op{kind: '+', old: x0, new: y, line: b[y]})
+			}
+		case y0 == y1:
+			for x := x0; x < x1; x++ {
+				ops = append(ops, op{kind: '-', old: x, new: y0, line: a[x]})
+			}
+		default:
+			x, y, u, v := middleSnake(a[x0:x1], b[y0:y1])
+			walk(x0, x0+x, y0, y0+y)
+			for i := 0; i < u-x; i++ {
+				ops = append(ops, op{kind: ' ', old: x0 + x + i, new: y0 + y + i, line: a[x0+x+i]})
+			}
+			walk(x0+u, x1, y0+v, y1)
+		}
+
+		for i := 0; i < suffix; i++ {
+			ops = append(ops, op{kind: ' ', old: x1 + i, new: y1 + i, line: a[x1+i]})
+		}
+	}
+	walk(0, len(a), 0, len(b))
+
+	return groupChanges(ops)
+}
+
+// middleSnake finds the snake, a run of shared lines, in the middle of a
+// shortest edit path from a to b by searching forward from the start and
+// backward from the end at the same time. It returns the snake as the line
+// numbers (x, y) where it starts and (u, v) where it ends. The first and last
+// lines of a and b must differ.
+func middleSnake(a, b []string) (x, y, u, v int) {
+	n, m := len(a), len(b)
+	delta := n - m
+	odd := delta%2 != 0
+	limit := (n + m + 1) / 2
+	offset := limit + 1
+	// forward[k] is the furthest x reached on diagonal k = x-y from the start;
+	// backward[k] is the furthest distance reached on diagonal k from the end.
+	forward := make([]int, 2*offset+1)
+	backward := make([]int, 2*offset+1)
+
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			if odd && delta-k >= -(d-1) && delta-k <= d-1 && x+backward[offset+delta-k] >= n {
+				return startX, startY, x, y
+			}
+		}
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && a[n-1-x] == b[m-1-y] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+			if !odd && delta-k >= -d && delta-k <= d && forward[offset+delta-k]+x >= n {
+				return n - x, m - y, n - startX, m - startY
+			}
+		}
+	}
+
+	// Unreachable: a path of at most n+m edits always exists.
+	return 0, 0, 0, 0
+}
+
+// groupChanges reorders each run of changed lines so that the removed lines
+// come before the added ones, as diff -u shows them.
+func groupChanges(ops []op) []op {
+	grouped := make([]op, 0, len(ops))
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			grouped = append(grouped, ops[i])
+			i++
+			continue
+		}
+
+		end := i
+		for end < len(ops) && ops[end].kind != ' ' {
+			end++
+		}
+		x, y := ops[i].old, ops[i].new
+		removed, added := 0, 0
+		for _, o := range ops[i:end] {
+			if o.kind == '-' {
+				grouped = append(grouped, op{kind: '-', old: x + removed, new: y, line: o.line})
+				removed++
+			}
+		}
+		for _, o := range ops[i:end] {
+			if o.kind == '+' {
+				grouped = append(grouped, op{kind: '+', old: x + removed, new: y + added, line: o.line})
+				added++
+			}
+		}
+		i = end
+	}
+	return grouped
+}
+
+// Colorize highlights a unified diff for terminals using ANSI escape codes:
+// file headers in bold, hunk headers in cyan, removed lines in red and
+// added lines in green.
+func Colorize(diff string) string {
+	var out strings.Builder
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		text := strings.TrimSuffix(line, "\n")
+		color := ""
+		switch {
+		case strings.HasPrefix(text, "--- "), strings.HasPrefix(text, "+++ "):
+			color = "\033[1m"
+		case strings.HasPrefix(text, "@@"):
+			color = "\033[36m"
+		case strings.HasPrefix(text, "-"):
+			color = "\033[31m"
+		case strings.HasPrefix(text, "+"):
+			color = "\033[32m"
+		}
+		if color == "" || text == "" {
+			out.WriteString(line)
+			continue
+		}
+		out.WriteString(color + text + "\033[0m" + line[len(text):])
+	}
+	return out.String()
+}
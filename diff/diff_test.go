@@ -0,0 +1,169 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	lines := func(s ...string) string { return strings.Join(s, "\n") + "\n" }
+
+	tests := []struct {
+		name     string
+		old, new string
+		context  int
+		want     string
+	}{
+		{
+			name: "equal",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+			want: "",
+		},
+		{
+			name:    "change in the middle",
+			old:     lines("a", "b", "c", "d", "e"),
+			new:     lines("a", "b", "C", "d", "e"),
+			context: 1,
+			want:    lines("--- a", "+++ b", "@@ -2,3 +2,3 @@", " b", "-c", "+C", " d"),
+		},
+		{
+			name:    "separate hunks",
+			old:     lines("a", "b", "c", "d", "e", "f"),
+			new:     lines("A", "b", "c", "d", "e", "F"),
+			context: 1,
+			want:    lines("--- a", "+++ b", "@@ -1,2 +1,2 @@", "-a", "+A", " b", "@@ -5,2 +5,2 @@", " e", "-f", "+F"),
+		},
+		{
+			name:    "overlapping context joins hunks",
+			old:     lines("a", "b", "c", "d"),
+			new:     lines("A", "b", "c", "D"),
+			context: 1,
+			want:    lines("--- a", "+++ b", "@@ -1,4 +1,4 @@", "-a", "+A", " b", " c", "-d", "+D"),
+		},
+		{
+			name:    "insertion into empty text",
+			old:     "",
+			new:     lines("a"),
+			context: 3,
+			want:    lines("--- a", "+++ b", "@@ -0,0 +1 @@", "+a"),
+		},
+		{
+			name:    "missing final newline",
+			old:     "a\nb",
+			new:     "a\nb\n",
+			context: 0,
+			want:    lines("--- a", "+++ b", "@@ -2 +2 @@", "-b", `\ No newline at end of file`, "+b"),
+		},
+		{
+			name:    "deletion and insertion",
+			old:     lines("a", "b", "c"),
+			new:     lines("b", "c", "d"),
+			context: 0,
+			want:    lines("--- a", "+++ b", "@@ -1 +0,0 @@", "-a", "@@ -3,0 +3 @@", "+d"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Unified("a", "b", tt.old, tt.new, tt.context)
+			if got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBytesChanged(t *testing.T) {
+	long := strings.Repeat("unchanged line\n", 1000)
+
+	tests := []struct {
+		before, after string
+		want          int
+	}{
+		{"same", "same", 0},
+		{"hello world", "hello there", 5},
+		{"aaa", "aaaa", 1},
+		{"abc", "", 3},
+		{"x = 1\n", "x = 10\n", 1},
+		{"a\nb\n", "a\nc\nb\n", 2},
+		{"x" + long + "end\n", "y" + long + "end\n!", 2},
+	}
+	for _, tt := range tests {
+		if got := BytesChanged(tt.before, tt.after); got != tt.want {
+			t.Errorf("BytesChanged(%q, %q) = %d, want %d", tt.before, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestEditsAreShortest(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	randomLines := func() []string {
+		lines := make([]string, rng.IntN(12))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.IntN(3)))
+		}
+		return lines
+	}
+
+	for range 2000 {
+		a, b := randomLines(), randomLines()
+		ops := edits(a, b)
+
+		var gotA, gotB []string
+		changes := 0
+		for _, o := range ops {
+			if o.kind != '+' {
+				if o.old != len(gotA) {
+					t.Fatalf("edits(%q, %q): op %+v at old line %d", a, b, o, len(gotA))
+				}
+				gotA = append(gotA, o.line)
+			}
+			if o.kind != '-' {
+				if o.new != len(gotB) {
+					t.Fatalf("edits(%q, %q): op %+v at new line %d", a, b, o, len(gotB))
+				}
+				gotB = append(gotB, o.line)
+			}
+			if o.kind != ' ' {
+				changes++
+			}
+		}
+		if strings.Join(gotA, "") != strings.Join(a, "") || strings.Join(gotB, "") != strings.Join(b, "") {
+			t.Fatalf("edits(%q, %q) = %+v does not turn one into the other", a, b, ops)
+		}
+
+		// The shortest script keeps a longest common subsequence.
+		lcs := make([][]int, len(a)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(b)+1)
+		}
+		for i := len(a) - 1; i >= 0; i-- {
+			for j := len(b) - 1; j >= 0; j-- {
+				if a[i] == b[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		if want := len(a) + len(b) - 2*lcs[0][0]; changes != want {
+			t.Fatalf("edits(%q, %q) has %d changes, want %d", a, b, changes, want)
+		}
+	}
+}
+
+func TestEditsEveryLineChanged(t *testing.T) {
+	var before, after strings.Builder
+	for i := range 4000 {
+		fmt.Fprintf(&before, "line %d\n", i)
+		fmt.Fprintf(&after, "line %d\r\n", i)
+	}
+
+	ops := edits(splitLines(before.String()), splitLines(after.String()))
+	if len(ops) != 8000 || ops[0].kind != '-' || ops[3999].kind != '-' || ops[4000].kind != '+' {
+		t.Errorf("expected 4000 removed lines followed by 4000 added lines, got %d ops", len(ops))
+	}
+}
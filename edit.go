@@ -0,0 +1,115 @@
+package texted
+
+import (
+	"os"
+
+	"github.com/dhamidi/texted/edlisp"
+)
+
+// The steps of editing a file that a FileResult can fail at.
+const (
+	// StageRead is reading the file.
+	StageRead = "read"
+	// StageScript is running the script on the content. With Options.Atomic,
+	// files that fail because another file did are at this stage, too.
+	StageScript = "script"
+	// StageBackup is writing the backup of the file.
+	StageBackup = "backup"
+	// StageWrite is writing the edited content.
+	StageWrite = "write"
+	// StageRestore is restoring the file after an atomic edit failed.
+	StageRestore = "restore"
+)
+
+// FileResult is the outcome of editing one file with Options.EditFiles.
+type FileResult struct {
+	Filename string
+
+	// Original is the content the file had when it was read.
+	Original string
+
+	// Run is the result of the script, or nil if the file could not be
+	// read, was skipped or the script failed on it.
+	Run *RunResult
+
+	// Output holds the text the script printed while editing the file.
+	Output edlisp.CapturedOutput
+
+	// Skipped is ErrBinary or ErrGenerated if the file was left alone
+	// because of its content, as decided by Options.CheckContent.
+	Skipped error
+
+	// Err tells why the file was not edited, and Stage at which step.
+	// Err is ErrAborted for files of an atomic edit that were not written
+	// because another file failed, and the *CommitError for the file whose
+	// write made it fail.
+	Err   error
+	Stage string
+
+	// BackedUp and Written tell whether the backup and the edited file
+	// have been written.
+	BackedUp bool
+	Written  bool
+}
+
+// EditFiles runs program on files as configured by o and calls report with
+// the result of every file, in the order of files, as soon as the file and
+// those before it are done. Up to o.Jobs files are edited at the same time.
+// Unless o.DryRun is set, every file the script succeeds on is written, after
+// backing it up if o.Backup is set. With o.Atomic, the files are only written
+// if the script succeeds on all of them, so they are reported once all have run.
+// Every name in files should refer to a different file; see UniqueFiles.
+func (o Options) EditFiles(program *Program, files []string, report func(*FileResult)) {
+	if o.Atomic && !o.DryRun {
+		o.editFilesAtomic(program, files, report)
+		return
+	}
+
+	InOrder(len(files), o.jobs(), func(i int) *FileResult {
+		file := runFile(program, files[i])
+		if file.Run != nil && !o.DryRun {
+			o.writeResult(file)
+		}
+		return file
+	}, func(i int, file *FileResult) {
+		report(file)
+	})
+}
+
+// runFile reads a file and runs program on its content.
+func runFile(program *Program, filename string) *FileResult {
+	file := &FileResult{Filename: filename}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		file.Err, file.Stage = err, StageRead
+		return file
+	}
+	file.Original = string(content)
+	if file.Skipped = program.CheckContent(file.Original); file.Skipped != nil {
+		return file
+	}
+
+	file.Run, err = program.Execute(file.Original, &file.Output)
+	if err != nil {
+		file.Err, file.Stage = err, StageScript
+	}
+	return file
+}
+
+// writeResult backs up the file if o.Backup is set and writes its edited content.
+func (o Options) writeResult(file *FileResult) {
+	if o.Backup != "" {
+		if err := WriteBackup(file.Filename, o.Backup, o.Write); err != nil {
+			file.Err, file.Stage = err, StageBackup
+			return
+		}
+		file.BackedUp = true
+	}
+
+	if err := WriteFile(file.Filename, []byte(file.Run.Output), o.Write); err != nil {
+		file.Err, file.Stage = err, StageWrite
+		return
+	}
+	file.Written = true
+}
@@ -0,0 +1,82 @@
+package edlisp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MissingArgumentsError reports script arguments that were used but not provided.
+type MissingArgumentsError struct {
+	// Names lists the missing arguments in alphabetical order.
+	Names []string
+}
+
+// Error implements the error interface.
+func (e *MissingArgumentsError) Error() string {
+	return fmt.Sprintf("missing script arguments: %s", strings.Join(e.Names, ", "))
+}
+
+// BindArguments returns a copy of program in which every (arg "NAME") is
+// replaced by the value of NAME in args as a String.
+// Parsers turn placeholders such as $NAME into (arg "NAME"), so binding the
+// parsed program fills in the placeholders without quoting issues.
+// If arguments are missing, BindArguments reports all of them in a
+// *MissingArgumentsError; program itself is never modified.
+func BindArguments(program []Value, args map[string]string) ([]Value, error) {
+	missing := make(map[string]bool)
+	bound := make([]Value, len(program))
+	for i, expr := range program {
+		bound[i] = bindArguments(expr, args, missing)
+	}
+
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, &MissingArgumentsError{Names: names}
+	}
+
+	return bound, nil
+}
+
+// bindArguments replaces the argument references in expr, recording missing arguments.
+func bindArguments(expr Value, args map[string]string, missing map[string]bool) Value {
+	list, ok := expr.(*List)
+	if !ok {
+		return expr
+	}
+
+	if name, ok := argumentName(list); ok {
+		value, exists := args[name]
+		if !exists {
+			missing[name] = true
+			return expr
+		}
+		return NewString(value)
+	}
+
+	elements := make([]Value, len(list.Elements))
+	for i, element := range list.Elements {
+		elements[i] = bindArguments(element, args, missing)
+	}
+	return NewList(elements...)
+}
+
+// argumentName returns NAME if list is (arg "NAME").
+func argumentName(list *List) (string, bool) {
+	if list.Len() != 2 {
+		return "", false
+	}
+	symbol, ok := list.Get(0).(*Symbol)
+	if !ok || symbol.Name != "arg" {
+		return "", false
+	}
+	name, ok := list.Get(1).(*String)
+	if !ok {
+		return "", false
+	}
+	return name.Value, true
+}
@@ -0,0 +1,63 @@
+package edlisp
+
+// BooleanKind represents the kind for boolean values.
+type BooleanKind struct{}
+
+// KindName returns the unique name for boolean kind.
+func (kind *BooleanKind) KindName() string {
+	return "boolean"
+}
+
+// TheBooleanKind is the singleton instance of BooleanKind.
+var TheBooleanKind = &BooleanKind{}
+
+// Boolean represents a truth value in texted expressions.
+// The canonical true value is T; predicates signal falsity with Nil.
+type Boolean struct {
+	Value bool
+}
+
+// T is the canonical true value, written as t in scripts.
+var T = &Boolean{Value: true}
+
+// Kind returns the ValueKind for booleans.
+func (b *Boolean) Kind() ValueKind {
+	return TheBooleanKind
+}
+
+// NewBoolean creates a new Boolean with the given value.
+func NewBoolean(value bool) *Boolean {
+	return &Boolean{Value: value}
+}
+
+// String returns the string representation of the boolean.
+func (b *Boolean) String() string {
+	if b.Value {
+		return "t"
+	}
+	return "nil"
+}
+
+// Bool converts a Go boolean into the canonical texted truth value:
+// T for true and Nil for false.
+func Bool(value bool) Value {
+	if value {
+		return T
+	}
+	return Nil
+}
+
+// IsTrue reports whether a value counts as true in texted.
+// Nil, a Go nil value and a false Boolean are false; everything else is true.
+func IsTrue(value Value) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case *Null:
+		return false
+	case *Boolean:
+		return v.Value
+	default:
+		return true
+	}
+}
@@ -0,0 +1,72 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinAppendToFile appends the text between START and END to FILE.
+// The file is created if it does not exist. The buffer is not modified.
+// The text is written with the encoding and line endings of the buffer.
+// The file is written through the FileSystem of the environment; if there is
+// none, file access is disabled and an error is returned.
+func BuiltinAppendToFile(args []Value, buffer *Buffer) (Value, error) {
+	text, name, err := regionForFile("append-to-file", args, buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	fsys, err := buffer.fileSystem()
+	if err != nil {
+		return nil, fmt.Errorf("append-to-file: %w", err)
+	}
+
+	coding := buffer.Coding()
+	coding.BOM = false
+	encoded, err := coding.Encode(text)
+	if err != nil {
+		return nil, fmt.Errorf("append-to-file: %w", err)
+	}
+	if err := fsys.AppendFile(name, []byte(encoded)); err != nil {
+		return nil, fmt.Errorf("append-to-file: %w", err)
+	}
+
+	return NewString(""), nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "append-to-file",
+		Summary:     "Append part of the buffer to a file",
+		Description: "Appends the text between START and END to FILE, creating it if necessary. START and END may be given in any order and are clamped to the accessible portion of the buffer. The buffer itself is not modified. The text is written with the encoding and line endings of the buffer, without a byte order mark; appending fails if the encoding cannot represent the text. File access is disabled by default; it has to be enabled with `texted edit --allow-fs DIR` or the matching MCP server option, and FILE is then a slash-separated path relative to DIR that may not leave it.",
+		Category:    "file",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "start",
+				Type:        "number",
+				Description: "Start position of the text to append",
+				Optional:    false,
+			},
+			{
+				Name:        "end",
+				Type:        "number",
+				Description: "End position of the text to append",
+				Optional:    false,
+			},
+			{
+				Name:        "file",
+				Type:        "string",
+				Description: "Path of the file to append to, relative to the allowed directory",
+				Optional:    false,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Collect the current line in a log file",
+				Input:       `mark-line; append-to-file (region-beginning) (region-end) "matches.log"`,
+				Buffer:      "TODO: fix this\n",
+				Output:      "matches.log ends with \"TODO: fix this\\n\"",
+			},
+		},
+		SeeAlso: []string{"write-region", "insert-file-contents", "file-exists-p"},
+	})
+}
@@ -0,0 +1,60 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinArg returns the value of the script argument NAME.
+// Usually the reference is replaced before evaluation by BindArguments, which
+// also reports missing arguments up front; BuiltinArg handles the remaining
+// cases, such as names computed at run time, from the Args of the environment.
+func BuiltinArg(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("arg expects 1 argument, got %d", len(args))
+	}
+
+	if !IsA(args[0], TheStringKind) {
+		return nil, fmt.Errorf("arg expects a string argument")
+	}
+
+	name := args[0].(*String).Value
+	if env := buffer.State().env; env != nil {
+		if value, exists := env.Args[name]; exists {
+			return NewString(value), nil
+		}
+	}
+
+	return nil, &MissingArgumentsError{Names: []string{name}}
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "arg",
+		Summary:     "Return the value of a script argument",
+		Description: "Returns the value of the script argument NAME as a string. Arguments are passed with `texted edit --arg NAME=VALUE`, the args object of the MCP tools or Options.Args in Go. In scripts, $NAME is shorthand for (arg \"NAME\"). References with a literal name are replaced before the script runs, so a missing argument is reported before any edit is made.",
+		Category:    "control",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "name",
+				Type:        "string",
+				Description: "Name of the argument",
+				Optional:    false,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Rename with --arg OLD=foo --arg NEW=bar",
+				Input:       `search-forward $OLD; replace-match $NEW`,
+				Buffer:      "call foo()",
+				Output:      "call bar()",
+			},
+			{
+				Description: "The same in S-expression syntax",
+				Input:       `(search-forward (arg "OLD"))`,
+				Buffer:      "call foo()",
+				Output:      "Point is after foo",
+			},
+		},
+		SeeAlso: []string{"load"},
+	})
+}
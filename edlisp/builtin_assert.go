@@ -0,0 +1,65 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinAssert aborts the script unless its condition is true.
+// The condition is usually the result of a predicate such as looking-at.
+// Nil and false fail the assertion; every other value passes it.
+// An optional message describes the intent and is reported in the error.
+// Returns t when the assertion holds.
+func BuiltinAssert(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("assert expects 1 or 2 arguments, got %d", len(args))
+	}
+
+	message, err := optionalMessage("assert", args, 1, fmt.Sprintf("%v is not true", args[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsTrue(args[0]) {
+		return nil, NewAssertionFailed("assert", message)
+	}
+
+	return T, nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "assert",
+		Summary:     "Abort the script unless a condition is true",
+		Description: "Checks that the condition is true and aborts the script with an assertion-failed error otherwise. The condition is usually the result of a predicate such as looking-at or string-match. Nil and false fail the assertion; every other value passes it. The optional message is reported in the error so that the intent of the check is visible. Returns t when the assertion holds.",
+		Category:    "assertion",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "condition",
+				Type:        "any",
+				Description: "Value that must be true",
+				Optional:    false,
+			},
+			{
+				Name:        "message",
+				Type:        "string",
+				Description: "Message reported when the assertion fails",
+				Optional:    true,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Check a predicate before editing",
+				Input:       `assert (looking-at "package") "file must start with a package clause"`,
+				Buffer:      "package main",
+				Output:      "Returns t",
+			},
+			{
+				Description: "Failing assertion",
+				Input:       `assert (string-match "x" "abc") "no x found"`,
+				Buffer:      "",
+				Output:      "Error: assertion failed: no x found",
+			},
+		},
+		SeeAlso: []string{"error", "assert-looking-at", "looking-at"},
+	})
+}
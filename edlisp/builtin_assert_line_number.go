@@ -0,0 +1,75 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinAssertLineNumber aborts the script unless point is on the given line.
+// Lines are numbered from 1, as in line-number-at-pos.
+// An optional message describes the intent and is reported in the error.
+// Returns t when the assertion holds.
+func BuiltinAssertLineNumber(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("assert-line-number expects 1 or 2 arguments, got %d", len(args))
+	}
+
+	if !IsA(args[0], TheNumberKind) {
+		return nil, fmt.Errorf("assert-line-number expects a number argument")
+	}
+
+	expected := args[0].(*Number).Int()
+	line, err := BuiltinLineNumberAtPos(nil, buffer)
+	if err != nil {
+		return nil, err
+	}
+	actual := line.(*Number).Int()
+
+	message, err := optionalMessage("assert-line-number", args, 1, fmt.Sprintf("expected point to be on line %d, but it is on line %d", expected, actual))
+	if err != nil {
+		return nil, err
+	}
+
+	if actual != expected {
+		return nil, NewAssertionFailed("assert-line-number", message)
+	}
+
+	return T, nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "assert-line-number",
+		Summary:     "Abort the script unless point is on a given line",
+		Description: "Checks that point is on the given 1-based line, as reported by line-number-at-pos, and aborts the script with an assertion-failed error otherwise. The optional message is reported in the error. Returns t when the assertion holds.",
+		Category:    "assertion",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "line",
+				Type:        "number",
+				Description: "Expected line number (1-based)",
+				Optional:    false,
+			},
+			{
+				Name:        "message",
+				Type:        "string",
+				Description: "Message reported when the assertion fails",
+				Optional:    true,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Check where a search ended",
+				Input:       `search-forward "Second"; assert-line-number 2`,
+				Buffer:      "First line\nSecond line",
+				Output:      "Returns t",
+			},
+			{
+				Description: "Failing assertion",
+				Input:       `assert-line-number 2 "header must be a single line"`,
+				Buffer:      "First line\nSecond line",
+				Output:      "Error: assertion failed: header must be a single line",
+			},
+		},
+		SeeAlso: []string{"line-number-at-pos", "goto-line", "assert"},
+	})
+}
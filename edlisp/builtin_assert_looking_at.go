@@ -0,0 +1,73 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinAssertLookingAt aborts the script unless the text at point matches the pattern.
+// The pattern is interpreted exactly like the argument of looking-at.
+// An optional message describes the intent and is reported in the error.
+// Returns t when the assertion holds. Does not move point.
+func BuiltinAssertLookingAt(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("assert-looking-at expects 1 or 2 arguments, got %d", len(args))
+	}
+
+	if !IsA(args[0], TheStringKind) {
+		return nil, fmt.Errorf("assert-looking-at expects a string pattern")
+	}
+
+	pattern := args[0].(*String)
+	message, err := optionalMessage("assert-looking-at", args, 1, fmt.Sprintf("expected text at point %d to match %q", buffer.Point(), pattern.Value))
+	if err != nil {
+		return nil, err
+	}
+
+	matched, err := BuiltinLookingAt(args[:1], buffer)
+	if err != nil {
+		return nil, err
+	}
+	if !IsTrue(matched) {
+		return nil, NewAssertionFailed("assert-looking-at", message)
+	}
+
+	return T, nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "assert-looking-at",
+		Summary:     "Abort the script unless the text at point matches a pattern",
+		Description: "Checks that the text at point matches the given pattern, exactly like looking-at, and aborts the script with an assertion-failed error otherwise. The optional message is reported in the error. Returns t when the assertion holds. Does not move point or modify the buffer.",
+		Category:    "assertion",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "pattern",
+				Type:        "string",
+				Description: "Pattern to match (literal string or regular expression)",
+				Optional:    false,
+			},
+			{
+				Name:        "message",
+				Type:        "string",
+				Description: "Message reported when the assertion fails",
+				Optional:    true,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Make sure point is at a function definition",
+				Input:       `search-forward "\n"; assert-looking-at "func" "expected a function on line 2"`,
+				Buffer:      "package main\nfunc main() {}",
+				Output:      "Returns t",
+			},
+			{
+				Description: "Failing assertion",
+				Input:       `assert-looking-at "func" "expected a function"`,
+				Buffer:      "package main",
+				Output:      "Error: assertion failed: expected a function",
+			},
+		},
+		SeeAlso: []string{"looking-at", "assert-looking-back", "assert"},
+	})
+}
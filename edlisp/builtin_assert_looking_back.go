@@ -0,0 +1,73 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinAssertLookingBack aborts the script unless the text before point matches the pattern.
+// The pattern is interpreted exactly like the argument of looking-back.
+// An optional message describes the intent and is reported in the error.
+// Returns t when the assertion holds. Does not move point.
+func BuiltinAssertLookingBack(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("assert-looking-back expects 1 or 2 arguments, got %d", len(args))
+	}
+
+	if !IsA(args[0], TheStringKind) {
+		return nil, fmt.Errorf("assert-looking-back expects a string pattern")
+	}
+
+	pattern := args[0].(*String)
+	message, err := optionalMessage("assert-looking-back", args, 1, fmt.Sprintf("expected text before point %d to match %q", buffer.Point(), pattern.Value))
+	if err != nil {
+		return nil, err
+	}
+
+	matched, err := BuiltinLookingBack(args[:1], buffer)
+	if err != nil {
+		return nil, err
+	}
+	if !IsTrue(matched) {
+		return nil, NewAssertionFailed("assert-looking-back", message)
+	}
+
+	return T, nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "assert-looking-back",
+		Summary:     "Abort the script unless the text before point matches a pattern",
+		Description: "Checks that the text ending at point matches the given pattern, exactly like looking-back, and aborts the script with an assertion-failed error otherwise. The optional message is reported in the error. Returns t when the assertion holds. Does not move point or modify the buffer.",
+		Category:    "assertion",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "pattern",
+				Type:        "string",
+				Description: "Pattern to match (literal string or regular expression)",
+				Optional:    false,
+			},
+			{
+				Name:        "message",
+				Type:        "string",
+				Description: "Message reported when the assertion fails",
+				Optional:    true,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Make sure a search stopped after an opening brace",
+				Input:       `search-forward "{"; assert-looking-back "main() {" "expected the body of main"`,
+				Buffer:      "package main\nfunc main() {}",
+				Output:      "Returns t",
+			},
+			{
+				Description: "Failing assertion",
+				Input:       `end-of-buffer; assert-looking-back "}" "expected a closing brace"`,
+				Buffer:      "package main",
+				Output:      "Error: assertion failed: expected a closing brace",
+			},
+		},
+		SeeAlso: []string{"looking-back", "assert-looking-at", "assert"},
+	})
+}
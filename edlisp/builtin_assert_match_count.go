@@ -0,0 +1,93 @@
+package edlisp
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// BuiltinAssertMatchCount aborts the script unless the pattern occurs the given number of times.
+// The whole accessible portion of the buffer is searched, independent of point.
+// If the pattern is a valid regular expression, non-overlapping regexp matches are counted.
+// If the pattern is not a valid regexp, it falls back to counting literal occurrences.
+// An optional message describes the intent and is reported in the error.
+// Returns t when the assertion holds.
+func BuiltinAssertMatchCount(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, fmt.Errorf("assert-match-count expects 2 or 3 arguments, got %d", len(args))
+	}
+
+	if !IsA(args[0], TheStringKind) {
+		return nil, fmt.Errorf("assert-match-count expects a string pattern")
+	}
+	if !IsA(args[1], TheNumberKind) {
+		return nil, fmt.Errorf("assert-match-count expects a number as second argument")
+	}
+
+	pattern := args[0].(*String)
+	expected := args[1].(*Number).Int()
+	content := buffer.String()[buffer.PointMin()-1 : buffer.PointMax()-1]
+
+	var actual int
+	re, err := regexp.Compile(pattern.Value)
+	if err != nil {
+		actual = strings.Count(content, pattern.Value)
+	} else {
+		actual = len(re.FindAllStringIndex(content, -1))
+	}
+
+	message, err := optionalMessage("assert-match-count", args, 2, fmt.Sprintf("expected %d matches of %q, found %d", expected, pattern.Value, actual))
+	if err != nil {
+		return nil, err
+	}
+
+	if actual != expected {
+		return nil, NewAssertionFailed("assert-match-count", message)
+	}
+
+	return T, nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "assert-match-count",
+		Summary:     "Abort the script unless a pattern occurs a given number of times",
+		Description: "Counts the non-overlapping matches of the pattern in the accessible portion of the buffer, independent of point, and aborts the script with an assertion-failed error unless the count equals the expected number. If the pattern is a valid regular expression, regexp matching is used; otherwise literal occurrences are counted. The optional message is reported in the error. Returns t when the assertion holds.",
+		Category:    "assertion",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "pattern",
+				Type:        "string",
+				Description: "Pattern to count (literal string or regular expression)",
+				Optional:    false,
+			},
+			{
+				Name:        "count",
+				Type:        "number",
+				Description: "Expected number of matches",
+				Optional:    false,
+			},
+			{
+				Name:        "message",
+				Type:        "string",
+				Description: "Message reported when the assertion fails",
+				Optional:    true,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Make sure a replacement target is unique",
+				Input:       `assert-match-count "oldName" 1 "oldName must be unique"; search-forward "oldName"; replace-match "newName"`,
+				Buffer:      "var oldName = 1",
+				Output:      "var newName = 1",
+			},
+			{
+				Description: "Failing assertion",
+				Input:       `assert-match-count "o" 1`,
+				Buffer:      "Hello world",
+				Output:      `Error: assertion failed: expected 1 matches of "o", found 2`,
+			},
+		},
+		SeeAlso: []string{"string-match", "re-search-forward", "assert"},
+	})
+}
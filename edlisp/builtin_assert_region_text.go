@@ -0,0 +1,82 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinAssertRegionText aborts the script unless the region contains exactly the given text.
+// The region is the text between mark and point, regardless of which comes first.
+// An optional message describes the intent and is reported in the error.
+// Returns t when the assertion holds.
+func BuiltinAssertRegionText(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("assert-region-text expects 1 or 2 arguments, got %d", len(args))
+	}
+
+	if !IsA(args[0], TheStringKind) {
+		return nil, fmt.Errorf("assert-region-text expects a string argument")
+	}
+
+	expected := args[0].(*String).Value
+
+	start := buffer.Mark()
+	end := buffer.Point()
+	if start > end {
+		start, end = end, start
+	}
+
+	region, err := BuiltinBufferSubstring([]Value{NewIntNumber(start), NewIntNumber(end)}, buffer)
+	if err != nil {
+		return nil, err
+	}
+	actual := region.(*String).Value
+
+	message, err := optionalMessage("assert-region-text", args, 1, fmt.Sprintf("expected region to be %q, got %q", expected, actual))
+	if err != nil {
+		return nil, err
+	}
+
+	if actual != expected {
+		return nil, NewAssertionFailed("assert-region-text", message)
+	}
+
+	return T, nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "assert-region-text",
+		Summary:     "Abort the script unless the region contains the given text",
+		Description: "Checks that the text between mark and point is exactly the given string and aborts the script with an assertion-failed error otherwise. It does not matter whether mark comes before or after point. The optional message is reported in the error. Returns t when the assertion holds.",
+		Category:    "assertion",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "text",
+				Type:        "string",
+				Description: "Expected contents of the region",
+				Optional:    false,
+			},
+			{
+				Name:        "message",
+				Type:        "string",
+				Description: "Message reported when the assertion fails",
+				Optional:    true,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Check a selection before replacing it",
+				Input:       `mark-word; assert-region-text "Hello"; replace-region "Goodbye"`,
+				Buffer:      "Hello world",
+				Output:      "Goodbye world",
+			},
+			{
+				Description: "Failing assertion",
+				Input:       `mark-word; assert-region-text "Goodbye"`,
+				Buffer:      "Hello world",
+				Output:      `Error: assertion failed: expected region to be "Goodbye", got "Hello"`,
+			},
+		},
+		SeeAlso: []string{"region-beginning", "region-end", "replace-region", "assert"},
+	})
+}
@@ -23,8 +23,8 @@ func BuiltinBackwardChar(args []Value, buffer *Buffer) (Value, error) {
 
 	newPos := buffer.Point() - count
 
-	if newPos < 1 {
-		newPos = 1
+	if newPos < buffer.PointMin() {
+		newPos = buffer.PointMin()
 	}
 
 	buffer.SetPoint(newPos)
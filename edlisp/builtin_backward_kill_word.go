@@ -23,18 +23,19 @@ func BuiltinBackwardKillWord(args []Value, buffer *Buffer) (Value, error) {
 		count = int(args[0].(*Number).Value)
 	}
 
-	content := buffer.String()
+	content := buffer.String()[:buffer.PointMax()-1]
 	startPos := buffer.Point() - 1 // Convert to 0-based
 	pos := startPos
+	minPos := buffer.PointMin() - 1
 
 	// Use the same logic as backward-word to find where to move backward to
-	for i := 0; i < count && pos > 0; i++ {
+	for i := 0; i < count && pos > minPos; i++ {
 		// Skip current non-word characters
-		for pos > 0 && !isLetter(content[pos-1]) {
+		for pos > minPos && !isLetter(content[pos-1]) {
 			pos--
 		}
 		// Skip word characters to get to beginning of word
-		for pos > 0 && isLetter(content[pos-1]) {
+		for pos > minPos && isLetter(content[pos-1]) {
 			pos--
 		}
 	}
@@ -45,9 +46,7 @@ func BuiltinBackwardKillWord(args []Value, buffer *Buffer) (Value, error) {
 	if endIndex > len(content) {
 		endIndex = len(content)
 	}
-	newContent := content[:pos] + content[endIndex:]
-	buffer.content.Reset()
-	buffer.content.WriteString(newContent)
+	buffer.replaceContent(pos, endIndex, "")
 
 	buffer.SetPoint(pos + 1) // Convert back to 1-based
 
@@ -25,14 +25,15 @@ func BuiltinBackwardWord(args []Value, buffer *Buffer) (Value, error) {
 
 	content := buffer.String()
 	pos := buffer.Point() - 1 // Convert to 0-based
+	minPos := buffer.PointMin() - 1
 
-	for i := 0; i < count && pos > 0; i++ {
+	for i := 0; i < count && pos > minPos; i++ {
 		// Skip current non-word characters
-		for pos > 0 && !isLetter(content[pos-1]) {
+		for pos > minPos && !isLetter(content[pos-1]) {
 			pos--
 		}
 		// Skip word characters to get to beginning of word
-		for pos > 0 && isLetter(content[pos-1]) {
+		for pos > minPos && isLetter(content[pos-1]) {
 			pos--
 		}
 	}
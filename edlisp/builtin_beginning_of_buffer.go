@@ -5,13 +5,13 @@ import (
 )
 
 // BuiltinBeginningOfBuffer moves the point to the very beginning of the buffer.
-// This is always position 1, regardless of buffer content.
+// This is position 1, or the start of the accessible portion when the buffer is narrowed.
 func BuiltinBeginningOfBuffer(args []Value, buffer *Buffer) (Value, error) {
 	if len(args) != 0 {
 		return nil, fmt.Errorf("beginning-of-buffer expects 0 arguments, got %d", len(args))
 	}
 
-	buffer.SetPoint(1)
+	buffer.SetPoint(buffer.PointMin())
 	return NewString(""), nil
 }
 
@@ -19,7 +19,7 @@ func init() {
 	RegisterDocumentation(FunctionDoc{
 		Name:        "beginning-of-buffer",
 		Summary:     "Move point to the very beginning of the buffer",
-		Description: "Moves the point to the very beginning of the buffer, which is always position 1. This is a simple navigation command that provides a quick way to jump to the start of any buffer content.",
+		Description: "Moves the point to the very beginning of the buffer, which is position 1, or to the start of the accessible portion when the buffer is narrowed. This is a simple navigation command that provides a quick way to jump to the start of any buffer content.",
 		Category:    "movement",
 		Parameters:  []ParameterDoc{},
 		Examples: []ExampleDoc{
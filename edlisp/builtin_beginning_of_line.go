@@ -14,17 +14,18 @@ func BuiltinBeginningOfLine(args []Value, buffer *Buffer) (Value, error) {
 
 	content := buffer.String()
 	pos := buffer.Point() - 1 // Convert to 0-based
+	minPos := buffer.PointMin() - 1
 
-	if pos < 0 {
-		buffer.SetPoint(1)
+	if pos < minPos {
+		buffer.SetPoint(buffer.PointMin())
 		return NewString(""), nil
 	}
 	if pos >= len(content) {
 		pos = len(content) - 1
 	}
 
-	// Move backward to find beginning of line
-	for pos > 0 && content[pos-1] != '\n' {
+	// Move backward to find beginning of line, or of the accessible portion
+	for pos > minPos && content[pos-1] != '\n' {
 		pos--
 	}
 
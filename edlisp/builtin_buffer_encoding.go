@@ -0,0 +1,34 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinBufferEncoding returns the character encoding of the current
+// buffer's file as a symbol such as utf-8 or iso-8859-1.
+//
+// Inside the buffer text is always UTF-8; the encoding is only applied
+// again when the buffer is written back.
+//
+// Category: buffer
+func BuiltinBufferEncoding(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("buffer-encoding expects 0 arguments, got %d", len(args))
+	}
+
+	return NewSymbol(string(buffer.Encoding())), nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "buffer-encoding",
+		Category:    "buffer",
+		Summary:     "Return the character encoding of the buffer",
+		Description: "Returns the character encoding the buffer's text is written with: one of the symbols utf-8, utf-16le, utf-16be, iso-8859-1 or windows-1252. The encoding is detected when the text is read, unless `texted edit --encoding` names it, and can be changed with set-buffer-encoding. Inside the buffer text is always UTF-8, so searches and insertions work the same for all encodings.",
+		Parameters:  []ParameterDoc{},
+		Examples: []ExampleDoc{
+			{Description: "Check the encoding of a UTF-8 file", Input: `buffer-encoding`, Buffer: "Grüße\n", Output: "utf-8"},
+		},
+		SeeAlso: []string{"set-buffer-encoding", "buffer-eol-type"},
+	})
+}
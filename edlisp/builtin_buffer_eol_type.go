@@ -0,0 +1,38 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinBufferEOLType returns the line ending convention of the current
+// buffer's file as one of the symbols unix, dos or mac.
+//
+// Inside the buffer lines always end with a newline; the convention is only
+// applied again when the buffer is written back.
+//
+// Category: buffer
+func BuiltinBufferEOLType(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("buffer-eol-type expects 0 arguments, got %d", len(args))
+	}
+
+	eol := buffer.Coding().EOL
+	if eol == "" {
+		eol = EOLUnix
+	}
+	return NewSymbol(string(eol)), nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "buffer-eol-type",
+		Category:    "buffer",
+		Summary:     "Return the line ending convention of the buffer",
+		Description: "Returns the line ending convention the buffer's text was read with: the symbol unix for \\n, dos for \\r\\n or mac for \\r. Inside the buffer every line ends with a single newline, so searches and line movement work the same for all conventions; the original line endings are restored when the file is written, unless `texted edit --eol` asks for another convention.",
+		Parameters:  []ParameterDoc{},
+		Examples: []ExampleDoc{
+			{Description: "Check the line endings of a Unix file", Input: `buffer-eol-type`, Buffer: "line one\nline two\n", Output: "unix"},
+		},
+		SeeAlso: []string{"buffer-size", "end-of-line"},
+	})
+}
@@ -0,0 +1,41 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinBufferString returns the text of the current buffer.
+// When the buffer is narrowed, only the accessible portion is returned.
+func BuiltinBufferString(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("buffer-string expects 0 arguments, got %d", len(args))
+	}
+
+	content := buffer.String()[buffer.PointMin()-1 : buffer.PointMax()-1]
+	return NewString(content), nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "buffer-string",
+		Summary:     "Return the text of the current buffer",
+		Description: "Returns the text of the current buffer as a string. When the buffer is narrowed, only the accessible portion is returned. Mostly useful to return the text built in a scratch buffer from with-temp-buffer.",
+		Category:    "buffer",
+		Parameters:  []ParameterDoc{},
+		Examples: []ExampleDoc{
+			{
+				Description: "Get the whole buffer text",
+				Input:       `buffer-string`,
+				Buffer:      "Hello world",
+				Output:      `"Hello world"`,
+			},
+			{
+				Description: "Build a string in a temporary buffer",
+				Input:       `with-temp-buffer (insert "world") (beginning-of-buffer) (insert "hello ") (buffer-string)`,
+				Buffer:      "",
+				Output:      `"hello world"`,
+			},
+		},
+		SeeAlso: []string{"buffer-substring", "with-temp-buffer", "insert-buffer-substring"},
+	})
+}
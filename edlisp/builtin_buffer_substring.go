@@ -12,7 +12,7 @@ import (
 //
 // Special handling:
 // - If END is -1, it means extract to the end of the buffer
-// - Positions are automatically adjusted to stay within buffer bounds
+// - Positions are automatically adjusted to stay within the accessible portion of the buffer
 // - If START >= END after adjustment, returns an empty string
 //
 // This function is useful for extracting text regions, copying content, or analyzing
@@ -54,18 +54,13 @@ func BuiltinBufferSubstring(args []Value, buffer *Buffer) (Value, error) {
 
 	// Handle special case: -1 means end of buffer
 	if end == -1 {
-		end = len(content) + 1
+		end = buffer.PointMax()
 	}
 
-	start-- // Convert to 0-based
-	end--   // Convert to 0-based
+	// Positions outside the accessible portion are moved to its ends
+	start = clampPosition(start, buffer.PointMin(), buffer.PointMax()) - 1 // Convert to 0-based
+	end = clampPosition(end, buffer.PointMin(), buffer.PointMax()) - 1     // Convert to 0-based
 
-	if start < 0 {
-		start = 0
-	}
-	if end > len(content) {
-		end = len(content)
-	}
 	if start >= end {
 		return NewString(""), nil
 	}
@@ -78,7 +73,7 @@ func init() {
 		Name:        "buffer-substring",
 		Category:    "buffer",
 		Summary:     "Extract a portion of the buffer content between two positions",
-		Description: "Extracts text from the buffer between the specified START and END positions. Positions are 1-based, where 1 is the first character. The extracted substring includes the character at START but excludes the character at END. If END is -1, extracts to the end of the buffer. Positions are automatically bounded to stay within the buffer.",
+		Description: "Extracts text from the buffer between the specified START and END positions. Positions are 1-based, where 1 is the first character. The extracted substring includes the character at START but excludes the character at END. If END is -1, extracts to the end of the buffer. Positions are automatically bounded to stay within the accessible portion of a narrowed buffer.",
 		Parameters: []ParameterDoc{
 			{Name: "start", Type: "number", Description: "The starting position (1-based, inclusive)"},
 			{Name: "end", Type: "number", Description: "The ending position (1-based, exclusive), or -1 for end of buffer"},
@@ -0,0 +1,34 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinCurrentBuffer returns the name of the buffer that forms currently operate on.
+// The buffer an evaluation starts in is called "*main*" unless the caller named it otherwise.
+func BuiltinCurrentBuffer(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("current-buffer expects 0 arguments, got %d", len(args))
+	}
+
+	return NewString(buffer.Name()), nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "current-buffer",
+		Summary:     "Return the name of the current buffer",
+		Description: "Returns the name of the buffer that forms currently operate on. The buffer an evaluation starts in, such as the file being edited, is called \"*main*\" unless the caller named it otherwise.",
+		Category:    "buffer",
+		Parameters:  []ParameterDoc{},
+		Examples: []ExampleDoc{
+			{
+				Description: "Get the name of the main buffer",
+				Input:       `current-buffer`,
+				Buffer:      "Hello",
+				Output:      `"*main*"`,
+			},
+		},
+		SeeAlso: []string{"set-buffer", "get-buffer-create", "with-temp-buffer"},
+	})
+}
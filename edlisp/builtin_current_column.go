@@ -43,8 +43,9 @@ func BuiltinCurrentColumn(args []Value, buffer *Buffer) (Value, error) {
 
 	content := buffer.String()
 	pos := buffer.Point() - 1 // Convert to 0-based
+	minPos := buffer.PointMin() - 1
 
-	if pos < 0 {
+	if pos < minPos {
 		return NewNumber(0), nil
 	}
 	if pos >= len(content) {
@@ -52,8 +53,8 @@ func BuiltinCurrentColumn(args []Value, buffer *Buffer) (Value, error) {
 	}
 
 	column := 0
-	// Count backward to find beginning of line
-	for i := pos; i >= 0 && content[i] != '\n'; i-- {
+	// Count backward to find beginning of line, or of the accessible portion
+	for i := pos; i >= minPos && content[i] != '\n'; i-- {
 		column++
 	}
 
@@ -65,7 +66,7 @@ func init() {
 		Name:        "current-column",
 		Category:    "position",
 		Summary:     "Return the column number of the current point position",
-		Description: "Returns the horizontal position of the point within the current line. The column is 0-based, where column 0 is the first character of the line. Calculated by counting characters from the beginning of the current line (after the last newline) to the point position.",
+		Description: "Returns the horizontal position of the point within the current line. The column is 0-based, where column 0 is the first character of the line. Calculated by counting characters from the beginning of the current line (after the last newline, or the start of the accessible portion of a narrowed buffer) to the point position.",
 		Parameters:  []ParameterDoc{},
 		Examples: []ExampleDoc{
 			{Description: "Get column at beginning of line", Input: `beginning-of-line; current-column`, Buffer: "Hello world", Output: "0"},
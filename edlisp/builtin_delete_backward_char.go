@@ -21,23 +21,20 @@ func BuiltinDeleteBackwardChar(args []Value, buffer *Buffer) (Value, error) {
 		count = int(args[0].(*Number).Value)
 	}
 
-	content := buffer.String()
 	pos := buffer.Point() - 1 // Convert to 0-based
 
 	// Delete count characters before the current position
 	endPos := pos
 	startPos := pos - count
-	if startPos < 0 {
-		startPos = 0
+	if startPos < buffer.PointMin()-1 {
+		startPos = buffer.PointMin() - 1
 	}
 
 	if startPos >= endPos {
 		return NewString(""), nil
 	}
 
-	newContent := content[:startPos] + content[endPos:]
-	buffer.content.Reset()
-	buffer.content.WriteString(newContent)
+	buffer.replaceContent(startPos, endPos, "")
 
 	// Update point to the new position
 	buffer.SetPoint(startPos + 1)
@@ -21,7 +21,7 @@ func BuiltinDeleteChar(args []Value, buffer *Buffer) (Value, error) {
 		count = int(args[0].(*Number).Value)
 	}
 
-	content := buffer.String()
+	content := buffer.String()[:buffer.PointMax()-1]
 	pos := buffer.Point() // 1-based position
 
 	if pos < 1 || pos > len(content) {
@@ -36,9 +36,7 @@ func BuiltinDeleteChar(args []Value, buffer *Buffer) (Value, error) {
 	}
 
 	// Delete characters starting at current position
-	newContent := content[:startPos] + content[endPos:]
-	buffer.content.Reset()
-	buffer.content.WriteString(newContent)
+	buffer.replaceContent(startPos, endPos, "")
 
 	return NewString(""), nil
 }
@@ -22,12 +22,12 @@ func BuiltinDeleteLine(args []Value, buffer *Buffer) (Value, error) {
 		count = int(args[0].(*Number).Value)
 	}
 
-	content := buffer.String()
+	content := buffer.String()[:buffer.PointMax()-1]
 	pos := buffer.Point() - 1 // Convert to 0-based
 
 	// Find beginning of current line
 	lineStart := pos
-	for lineStart > 0 && content[lineStart-1] != '\n' {
+	for lineStart > buffer.PointMin()-1 && content[lineStart-1] != '\n' {
 		lineStart--
 	}
 
@@ -42,9 +42,7 @@ func BuiltinDeleteLine(args []Value, buffer *Buffer) (Value, error) {
 		}
 	}
 
-	newContent := content[:lineStart] + content[lineEnd:]
-	buffer.content.Reset()
-	buffer.content.WriteString(newContent)
+	buffer.replaceContent(lineStart, lineEnd, "")
 
 	buffer.SetPoint(lineStart + 1) // Convert back to 1-based
 
@@ -20,23 +20,15 @@ func BuiltinDeleteRegion(args []Value, buffer *Buffer) (Value, error) {
 		start, end = end, start
 	}
 
-	content := buffer.String()
-	start-- // Convert to 0-based
-	end--   // Convert to 0-based
+	// Only the accessible portion of a narrowed buffer can be changed
+	start = clampPosition(start, buffer.PointMin(), buffer.PointMax()) - 1 // Convert to 0-based
+	end = clampPosition(end, buffer.PointMin(), buffer.PointMax()) - 1     // Convert to 0-based
 
-	if start < 0 {
-		start = 0
-	}
-	if end > len(content) {
-		end = len(content)
-	}
 	if start >= end {
 		return NewString(""), nil
 	}
 
-	newContent := content[:start] + content[end:]
-	buffer.content.Reset()
-	buffer.content.WriteString(newContent)
+	buffer.replaceContent(start, end, "")
 
 	// Set point to start of deleted region
 	buffer.SetPoint(start + 1)
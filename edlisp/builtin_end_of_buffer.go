@@ -5,14 +5,14 @@ import (
 )
 
 // BuiltinEndOfBuffer moves the point to the very end of the buffer.
-// This is always one position past the last character in the buffer.
+// This is one position past the last character in the buffer, or the end of the
+// accessible portion when the buffer is narrowed.
 func BuiltinEndOfBuffer(args []Value, buffer *Buffer) (Value, error) {
 	if len(args) != 0 {
 		return nil, fmt.Errorf("end-of-buffer expects 0 arguments, got %d", len(args))
 	}
 
-	content := buffer.String()
-	buffer.SetPoint(len(content) + 1)
+	buffer.SetPoint(buffer.PointMax())
 	return NewString(""), nil
 }
 
@@ -20,7 +20,7 @@ func init() {
 	RegisterDocumentation(FunctionDoc{
 		Name:        "end-of-buffer",
 		Summary:     "Move point to the very end of the buffer",
-		Description: "Moves the point to the very end of the buffer, which is one position past the last character. This position allows for inserting text at the end of the buffer content.",
+		Description: "Moves the point to the very end of the buffer, which is one position past the last character, or to the end of the accessible portion when the buffer is narrowed. This position allows for inserting text at the end of the buffer content.",
 		Category:    "movement",
 		Parameters:  []ParameterDoc{},
 		Examples: []ExampleDoc{
@@ -12,7 +12,7 @@ func BuiltinEndOfLine(args []Value, buffer *Buffer) (Value, error) {
 		return nil, fmt.Errorf("end-of-line expects 0 arguments, got %d", len(args))
 	}
 
-	content := buffer.String()
+	content := buffer.String()[:buffer.PointMax()-1]
 	pos := buffer.Point() - 1 // Convert to 0-based
 
 	if pos < 0 {
@@ -0,0 +1,45 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinError aborts the script unconditionally with the given message.
+// The resulting error is an AssertionFailed carrying the message verbatim.
+func BuiltinError(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("error expects 1 argument, got %d", len(args))
+	}
+
+	if !IsA(args[0], TheStringKind) {
+		return nil, fmt.Errorf("error expects a string argument")
+	}
+
+	return nil, NewAssertionFailed("error", args[0].(*String).Value)
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "error",
+		Summary:     "Abort the script with a message",
+		Description: "Aborts the script unconditionally with an assertion-failed error carrying the given message. Useful as the last step of a script path that must never be reached.",
+		Category:    "assertion",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "message",
+				Type:        "string",
+				Description: "Message reported in the error",
+				Optional:    false,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Stop the script",
+				Input:       `error "unsupported file layout"`,
+				Buffer:      "Hello world",
+				Output:      "Error: assertion failed: unsupported file layout",
+			},
+		},
+		SeeAlso: []string{"assert"},
+	})
+}
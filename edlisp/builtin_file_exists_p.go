@@ -0,0 +1,61 @@
+package edlisp
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// BuiltinFileExistsP returns t if FILE exists and nil otherwise.
+// The file is looked up through the FileSystem of the environment; if there
+// is none, file access is disabled and an error is returned.
+func BuiltinFileExistsP(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("file-exists-p expects 1 argument, got %d", len(args))
+	}
+
+	if !IsA(args[0], TheStringKind) {
+		return nil, fmt.Errorf("file-exists-p expects a string argument")
+	}
+
+	fsys, err := buffer.fileSystem()
+	if err != nil {
+		return nil, fmt.Errorf("file-exists-p: %w", err)
+	}
+
+	_, err = fs.Stat(fsys, args[0].(*String).Value)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("file-exists-p: %w", err)
+	}
+
+	return T, nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "file-exists-p",
+		Summary:     "Test whether a file exists",
+		Description: "Returns t if FILE exists and nil otherwise. File access is disabled by default; it has to be enabled with `texted edit --allow-fs DIR` or the matching MCP server option, and FILE is then a slash-separated path relative to DIR that may not leave it.",
+		Category:    "file",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "file",
+				Type:        "string",
+				Description: "Path of the file to test, relative to the allowed directory",
+				Optional:    false,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Only insert a header if the template exists",
+				Input:       `assert (file-exists-p "header.txt") "header template missing"; insert-file-contents "header.txt"`,
+				Buffer:      "body",
+				Output:      "The header is inserted before \"body\"",
+			},
+		},
+		SeeAlso: []string{"insert-file-contents", "write-region", "append-to-file"},
+	})
+}
@@ -21,13 +21,12 @@ func BuiltinForwardChar(args []Value, buffer *Buffer) (Value, error) {
 		count = int(args[0].(*Number).Value)
 	}
 
-	content := buffer.String()
 	newPos := buffer.Point() + count
 
-	if newPos < 1 {
-		newPos = 1
-	} else if newPos > len(content)+1 {
-		newPos = len(content) + 1
+	if newPos < buffer.PointMin() {
+		newPos = buffer.PointMin()
+	} else if newPos > buffer.PointMax() {
+		newPos = buffer.PointMax()
 	}
 
 	buffer.SetPoint(newPos)
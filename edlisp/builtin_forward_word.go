@@ -23,7 +23,7 @@ func BuiltinForwardWord(args []Value, buffer *Buffer) (Value, error) {
 		count = int(args[0].(*Number).Value)
 	}
 
-	content := buffer.String()
+	content := buffer.String()[:buffer.PointMax()-1]
 	pos := buffer.Point() - 1 // Convert to 0-based
 
 	for i := 0; i < count && pos < len(content); i++ {
@@ -0,0 +1,51 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinGetBufferCreate returns the name of the buffer called NAME, creating an empty buffer if necessary.
+// Buffers are referred to by name. Creating a buffer does not make it current; use set-buffer for that.
+func BuiltinGetBufferCreate(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("get-buffer-create expects 1 argument, got %d", len(args))
+	}
+
+	if !IsA(args[0], TheStringKind) {
+		return nil, fmt.Errorf("get-buffer-create expects a string argument")
+	}
+
+	name := args[0].(*String).Value
+	if name == "" {
+		return nil, fmt.Errorf("get-buffer-create expects a non-empty buffer name")
+	}
+
+	buffer.State().GetBufferCreate(name)
+	return NewString(name), nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "get-buffer-create",
+		Summary:     "Return a buffer by name, creating it if necessary",
+		Description: "Returns the name of the buffer called NAME. If no such buffer exists, an empty buffer with that name is created. Buffers are referred to by name. Creating a buffer does not make it current; use set-buffer to switch to it. All buffers live until the end of the evaluation.",
+		Category:    "buffer",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "name",
+				Type:        "string",
+				Description: "The name of the buffer",
+				Optional:    false,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Collect text in a scratch buffer",
+				Input:       `set-buffer (get-buffer-create "notes"); insert "TODO"`,
+				Buffer:      "Hello",
+				Output:      "The buffer \"notes\" contains \"TODO\"",
+			},
+		},
+		SeeAlso: []string{"set-buffer", "current-buffer", "with-temp-buffer"},
+	})
+}
@@ -21,11 +21,10 @@ func BuiltinGotoChar(args []Value, buffer *Buffer) (Value, error) {
 	num := args[0].(*Number)
 	pos := int(num.Value)
 
-	content := buffer.String()
-	if pos < 1 {
-		pos = 1
-	} else if pos > len(content)+1 {
-		pos = len(content) + 1
+	if pos < buffer.PointMin() {
+		pos = buffer.PointMin()
+	} else if pos > buffer.PointMax() {
+		pos = buffer.PointMax()
 	}
 
 	buffer.SetPoint(pos)
@@ -9,6 +9,7 @@ import (
 // Line numbers are 1-based. If the line number is less than 1, moves to line 1.
 // If the line number is greater than the total number of lines, moves to the last line.
 // The point is positioned at the beginning of the target line.
+// When the buffer is narrowed, lines are counted from the start of the accessible portion.
 func BuiltinGotoLine(args []Value, buffer *Buffer) (Value, error) {
 	if len(args) != 1 {
 		return nil, fmt.Errorf("goto-line expects 1 argument, got %d", len(args))
@@ -21,7 +22,7 @@ func BuiltinGotoLine(args []Value, buffer *Buffer) (Value, error) {
 	num := args[0].(*Number)
 	lineNum := int(num.Value)
 
-	content := buffer.String()
+	content := buffer.String()[buffer.PointMin()-1 : buffer.PointMax()-1]
 	lines := strings.Split(content, "\n")
 
 	if lineNum < 1 {
@@ -31,7 +32,7 @@ func BuiltinGotoLine(args []Value, buffer *Buffer) (Value, error) {
 	}
 
 	// Calculate position at beginning of target line
-	pos := 1
+	pos := buffer.PointMin()
 	for i := 0; i < lineNum-1; i++ {
 		pos += len(lines[i]) + 1 // +1 for newline
 	}
@@ -44,7 +45,7 @@ func init() {
 	RegisterDocumentation(FunctionDoc{
 		Name:        "goto-line",
 		Summary:     "Move point to the beginning of a specific line",
-		Description: "Moves the point to the beginning of the specified line number. Line numbers are 1-based. If the line number is less than 1, moves to line 1. If the line number is greater than the total number of lines, moves to the last line. The point is positioned at the beginning of the target line.",
+		Description: "Moves the point to the beginning of the specified line number. Line numbers are 1-based. If the line number is less than 1, moves to line 1. If the line number is greater than the total number of lines, moves to the last line. The point is positioned at the beginning of the target line. When the buffer is narrowed, lines are counted from the start of the accessible portion.",
 		Category:    "movement",
 		Parameters: []ParameterDoc{
 			{
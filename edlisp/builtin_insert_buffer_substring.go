@@ -0,0 +1,84 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinInsertBufferSubstring inserts text from another buffer at point in the current buffer.
+// Without START and END, the accessible portion of the other buffer is inserted.
+// Positions refer to the other buffer and are clamped to its accessible portion.
+// Point ends up after the inserted text.
+func BuiltinInsertBufferSubstring(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) != 1 && len(args) != 3 {
+		return nil, fmt.Errorf("insert-buffer-substring expects 1 or 3 arguments, got %d", len(args))
+	}
+
+	if !IsA(args[0], TheStringKind) {
+		return nil, fmt.Errorf("insert-buffer-substring expects a buffer name as first argument")
+	}
+
+	name := args[0].(*String).Value
+	source := buffer.State().GetBuffer(name)
+	if source == nil {
+		return nil, fmt.Errorf("insert-buffer-substring: no buffer named %q", name)
+	}
+
+	start, end := source.PointMin(), source.PointMax()
+	if len(args) == 3 {
+		if !IsA(args[1], TheNumberKind) || !IsA(args[2], TheNumberKind) {
+			return nil, fmt.Errorf("insert-buffer-substring expects number arguments for start and end")
+		}
+		start = clampPosition(args[1].(*Number).Int(), source.PointMin(), source.PointMax())
+		end = clampPosition(args[2].(*Number).Int(), source.PointMin(), source.PointMax())
+		if start > end {
+			start, end = end, start
+		}
+	}
+
+	buffer.Insert(source.String()[start-1 : end-1])
+	return NewString(""), nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "insert-buffer-substring",
+		Summary:     "Insert text from another buffer at point",
+		Description: "Inserts text from the buffer called NAME at point in the current buffer and moves point after it. Without START and END, the accessible portion of that buffer is inserted. START and END are positions in the other buffer; they may be given in any order and are clamped to its accessible portion. Signals an error if there is no buffer with that name.",
+		Category:    "buffer",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "name",
+				Type:        "string",
+				Description: "The name of the buffer to copy from",
+				Optional:    false,
+			},
+			{
+				Name:        "start",
+				Type:        "number",
+				Description: "Start position in the other buffer",
+				Optional:    true,
+			},
+			{
+				Name:        "end",
+				Type:        "number",
+				Description: "End position in the other buffer",
+				Optional:    true,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Copy a scratch buffer into the main buffer",
+				Input:       `set-buffer (get-buffer-create "tmp"); insert "Hi "; set-buffer "*main*"; insert-buffer-substring "tmp"`,
+				Buffer:      "there",
+				Output:      "Hi there",
+			},
+			{
+				Description: "Copy part of another buffer",
+				Input:       `insert-buffer-substring "template" 1 6`,
+				Buffer:      "",
+				Output:      "The first five characters of the buffer \"template\" are inserted",
+			},
+		},
+		SeeAlso: []string{"insert", "buffer-substring", "get-buffer-create", "set-buffer"},
+	})
+}
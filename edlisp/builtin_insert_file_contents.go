@@ -0,0 +1,66 @@
+package edlisp
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// BuiltinInsertFileContents inserts the contents of FILE at point.
+// Unlike insert, point stays before the inserted text.
+// The encoding of the file is detected, line endings are converted to
+// newlines and a byte order mark is dropped.
+// The file is read through the FileSystem of the environment; if there is
+// none, file access is disabled and an error is returned.
+// Returns the number of characters inserted.
+func BuiltinInsertFileContents(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("insert-file-contents expects 1 argument, got %d", len(args))
+	}
+
+	if !IsA(args[0], TheStringKind) {
+		return nil, fmt.Errorf("insert-file-contents expects a string argument")
+	}
+
+	fsys, err := buffer.fileSystem()
+	if err != nil {
+		return nil, fmt.Errorf("insert-file-contents: %w", err)
+	}
+
+	name := args[0].(*String).Value
+	content, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("insert-file-contents: %w", err)
+	}
+
+	text, _ := DecodeText(string(content))
+	pos := buffer.Point() - 1 // Convert to 0-based
+	buffer.replaceContent(pos, pos, text)
+
+	return NewNumber(float64(len(text))), nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "insert-file-contents",
+		Summary:     "Insert the contents of a file at point",
+		Description: "Inserts the contents of FILE at point. Unlike insert, point stays before the inserted text. The encoding of the file is detected, line endings are converted to newlines and a byte order mark is dropped, as when texted reads the file being edited. File access is disabled by default; it has to be enabled with `texted edit --allow-fs DIR` or the matching MCP server option, and FILE is then a slash-separated path relative to DIR that may not leave it. Returns the number of characters inserted.",
+		Category:    "file",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "file",
+				Type:        "string",
+				Description: "Path of the file to read, relative to the allowed directory",
+				Optional:    false,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Insert a license header from a template",
+				Input:       `beginning-of-buffer; insert-file-contents "templates/license.txt"`,
+				Buffer:      "package main",
+				Output:      "The license text is inserted before \"package main\"",
+			},
+		},
+		SeeAlso: []string{"write-region", "append-to-file", "file-exists-p", "insert"},
+	})
+}
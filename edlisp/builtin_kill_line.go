@@ -22,7 +22,7 @@ func BuiltinKillLine(args []Value, buffer *Buffer) (Value, error) {
 		count = int(args[0].(*Number).Value)
 	}
 
-	content := buffer.String()
+	content := buffer.String()[:buffer.PointMax()-1]
 	pos := buffer.Point() - 1 // Convert to 0-based
 
 	if pos < 0 || pos >= len(content) {
@@ -55,9 +55,7 @@ func BuiltinKillLine(args []Value, buffer *Buffer) (Value, error) {
 		}
 	}
 
-	newContent := content[:startPos] + content[lineEnd:]
-	buffer.content.Reset()
-	buffer.content.WriteString(newContent)
+	buffer.replaceContent(startPos, lineEnd, "")
 
 	return NewString(""), nil
 }
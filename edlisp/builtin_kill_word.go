@@ -23,7 +23,7 @@ func BuiltinKillWord(args []Value, buffer *Buffer) (Value, error) {
 		count = int(args[0].(*Number).Value)
 	}
 
-	content := buffer.String()
+	content := buffer.String()[:buffer.PointMax()-1]
 	startPos := buffer.Point() - 1 // Convert to 0-based
 	pos := startPos
 
@@ -38,9 +38,7 @@ func BuiltinKillWord(args []Value, buffer *Buffer) (Value, error) {
 		}
 	}
 
-	newContent := content[:startPos] + content[pos:]
-	buffer.content.Reset()
-	buffer.content.WriteString(newContent)
+	buffer.replaceContent(startPos, pos, "")
 
 	return NewString(""), nil
 }
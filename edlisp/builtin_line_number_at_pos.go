@@ -48,15 +48,17 @@ func BuiltinLineNumberAtPos(args []Value, buffer *Buffer) (Value, error) {
 	content := buffer.String()
 	pos := buffer.Point() - 1 // Convert to 0-based
 
-	if pos < 0 {
-		pos = 0
+	// Like goto-line, lines are counted from the start of the accessible portion
+	minPos := buffer.PointMin() - 1
+	if pos < minPos {
+		pos = minPos
 	}
-	if pos > len(content) {
-		pos = len(content)
+	if pos > buffer.PointMax()-1 {
+		pos = buffer.PointMax() - 1
 	}
 
 	lineNum := 1
-	for i := 0; i < pos; i++ {
+	for i := minPos; i < pos; i++ {
 		if content[i] == '\n' {
 			lineNum++
 		}
@@ -70,7 +72,7 @@ func init() {
 		Name:        "line-number-at-pos",
 		Category:    "position",
 		Summary:     "Return the line number of the current point position",
-		Description: "Returns the vertical position of the point within the buffer. Line numbers are 1-based, where line 1 is the first line. Lines are separated by newline characters. Calculated by counting newlines from the beginning of the buffer to the current point position.",
+		Description: "Returns the vertical position of the point within the buffer. Line numbers are 1-based, where line 1 is the first line. Lines are separated by newline characters. Calculated by counting newlines from the beginning of the buffer, or of the accessible portion of a narrowed buffer, to the current point position.",
 		Parameters:  []ParameterDoc{},
 		Examples: []ExampleDoc{
 			{Description: "Get line number at beginning", Input: `line-number-at-pos`, Buffer: "Hello world", Output: "1"},
@@ -0,0 +1,51 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinLoad evaluates the script file FILE as if its contents appeared in
+// place of the call and returns t.
+// FILE is looked up in the load path of the environment; the script format
+// is chosen by its extension. Errors inside FILE report the file and line.
+func BuiltinLoad(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("load expects 1 argument, got %d", len(args))
+	}
+
+	if !IsA(args[0], TheStringKind) {
+		return nil, fmt.Errorf("load expects a string argument")
+	}
+
+	if err := buffer.loadScript(args[0].(*String).Value); err != nil {
+		return nil, fmt.Errorf("load: %w", err)
+	}
+
+	return T, nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "load",
+		Summary:     "Evaluate the expressions of a script file",
+		Description: "Reads FILE from the load path, parses it and evaluates its expressions in the current buffer as if they appeared in place of the call, then returns t. The format is chosen by the extension: .elsh for shell-like syntax, .el for S-expressions and .json for JSON; other files are read as shell-like syntax. The load path is set with `texted edit --load-path DIR` and also contains the directory of the script given with -f. Loading a file that is already being loaded is an error, and errors inside FILE are reported as FILE:LINE.",
+		Category:    "control",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "file",
+				Type:        "string",
+				Description: "Slash-separated path of the script, relative to a directory of the load path",
+				Optional:    false,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Reuse a snippet that moves point to the import block",
+				Input:       `load "lib/imports.elsh"; insert "\t\"strings\"\n"`,
+				Buffer:      "package main\n\nimport (\n\t\"fmt\"\n)\n",
+				Output:      "The import is added wherever lib/imports.elsh left point",
+			},
+		},
+		SeeAlso: []string{"progn"},
+	})
+}
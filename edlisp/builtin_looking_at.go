@@ -7,7 +7,7 @@ import (
 )
 
 // BuiltinLookingAt checks if the text at the current point matches the given pattern.
-// Returns the symbol 't' if the pattern matches at the current position, 'nil' otherwise.
+// Returns t if the pattern matches at the current position, nil otherwise.
 // The pattern can be either a literal string or a regular expression.
 // If the pattern is a valid regular expression, it uses regexp matching.
 // If the pattern is not a valid regexp, it falls back to literal string matching.
@@ -22,11 +22,11 @@ func BuiltinLookingAt(args []Value, buffer *Buffer) (Value, error) {
 	}
 
 	pattern := args[0].(*String)
-	content := buffer.String()
-	pos := buffer.Point() - 1 // Convert to 0-based
+	content := buffer.String()[:buffer.PointMax()-1] // Stop at the end of the accessible portion
+	pos := buffer.Point() - 1                        // Convert to 0-based
 
 	if pos < 0 || pos >= len(content) {
-		return NewSymbol("nil"), nil
+		return Nil, nil
 	}
 
 	// Try to compile as regular expression
@@ -34,25 +34,25 @@ func BuiltinLookingAt(args []Value, buffer *Buffer) (Value, error) {
 	if err != nil {
 		// If not a valid regex, treat as literal string
 		if strings.HasPrefix(content[pos:], pattern.Value) {
-			return NewSymbol("t"), nil
+			return T, nil
 		}
-		return NewSymbol("nil"), nil
+		return Nil, nil
 	}
 
 	// Use regular expression matching
 	match := re.FindStringIndex(content[pos:])
 	if match != nil && match[0] == 0 {
-		return NewSymbol("t"), nil
+		return T, nil
 	}
 
-	return NewSymbol("nil"), nil
+	return Nil, nil
 }
 
 func init() {
 	RegisterDocumentation(FunctionDoc{
 		Name:        "looking-at",
 		Summary:     "Check if text at current position matches a pattern",
-		Description: "Checks if the text at the current point matches the given pattern. Returns the symbol 't' if the pattern matches at the current position, 'nil' otherwise. The pattern can be either a literal string or a regular expression. If the pattern is a valid regular expression, it uses regexp matching starting at the current position. If the pattern is not a valid regexp, it falls back to literal string matching. Does not move the point or modify the buffer in any way.",
+		Description: "Checks if the text at the current point matches the given pattern. Returns t if the pattern matches at the current position, nil otherwise. The pattern can be either a literal string or a regular expression. If the pattern is a valid regular expression, it uses regexp matching starting at the current position. If the pattern is not a valid regexp, it falls back to literal string matching. Does not move the point or modify the buffer in any way.",
 		Category:    "search",
 		Parameters: []ParameterDoc{
 			{
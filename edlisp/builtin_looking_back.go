@@ -7,7 +7,7 @@ import (
 )
 
 // BuiltinLookingBack checks if the text before the current point matches the given pattern.
-// Returns the symbol 't' if the pattern matches ending at the current position, 'nil' otherwise.
+// Returns t if the pattern matches ending at the current position, nil otherwise.
 // The pattern can be either a literal string or a regular expression.
 // If the pattern is a valid regular expression, it uses regexp matching on text before the point.
 // If the pattern is not a valid regexp, it falls back to literal string matching.
@@ -24,11 +24,11 @@ func BuiltinLookingBack(args []Value, buffer *Buffer) (Value, error) {
 	}
 
 	pattern := args[0].(*String)
-	content := buffer.String()
-	pos := buffer.Point() - 1 // Convert to 0-based
+	content := buffer.String()[buffer.PointMin()-1:] // Start at the beginning of the accessible portion
+	pos := buffer.Point() - buffer.PointMin()        // Convert to 0-based offset into content
 
 	if pos <= 0 {
-		return NewSymbol("nil"), nil
+		return Nil, nil
 	}
 
 	// Try to compile as regular expression
@@ -36,26 +36,26 @@ func BuiltinLookingBack(args []Value, buffer *Buffer) (Value, error) {
 	if err != nil {
 		// If not a valid regex, treat as literal string
 		if pos >= len(pattern.Value) && strings.HasSuffix(content[:pos], pattern.Value) {
-			return NewSymbol("t"), nil
+			return T, nil
 		}
-		return NewSymbol("nil"), nil
+		return Nil, nil
 	}
 
 	// Use regular expression matching on text before point
 	beforeText := content[:pos]
 	match := re.FindStringIndex(beforeText)
 	if match != nil && match[1] == len(beforeText) {
-		return NewSymbol("t"), nil
+		return T, nil
 	}
 
-	return NewSymbol("nil"), nil
+	return Nil, nil
 }
 
 func init() {
 	RegisterDocumentation(FunctionDoc{
 		Name:        "looking-back",
 		Summary:     "Check if text before current position matches a pattern",
-		Description: "Checks if the text before the current point matches the given pattern ending at the current position. Returns the symbol 't' if the pattern matches, 'nil' otherwise. The pattern can be either a literal string or a regular expression. For literal strings, checks if the text before point ends with the given string. For regular expressions, checks if there's a match that ends exactly at the current point. Does not move the point or modify the buffer in any way.",
+		Description: "Checks if the text before the current point matches the given pattern ending at the current position. Returns t if the pattern matches, nil otherwise. The pattern can be either a literal string or a regular expression. For literal strings, checks if the text before point ends with the given string. For regular expressions, checks if there's a match that ends exactly at the current point. Does not move the point or modify the buffer in any way.",
 		Category:    "search",
 		Parameters: []ParameterDoc{
 			{
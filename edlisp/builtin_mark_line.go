@@ -23,12 +23,13 @@ func BuiltinMarkLine(args []Value, buffer *Buffer) (Value, error) {
 		count = int(args[0].(*Number).Value)
 	}
 
-	content := buffer.String()
+	content := buffer.String()[:buffer.PointMax()-1]
 	pos := buffer.Point() - 1 // Convert to 0-based
+	minPos := buffer.PointMin() - 1
 
 	// Find beginning of current line
 	lineStart := pos
-	for lineStart > 0 && content[lineStart-1] != '\n' {
+	for lineStart > minPos && content[lineStart-1] != '\n' {
 		lineStart--
 	}
 
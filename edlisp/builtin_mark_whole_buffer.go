@@ -8,15 +8,15 @@ import (
 // This function creates a region that encompasses all text in the buffer by setting
 // the mark at the beginning of the buffer (position 1) and moving the point to the
 // end of the buffer. This is equivalent to manually setting mark at position 1 and
-// then moving to the end of the buffer.
+// then moving to the end of the buffer. When the buffer is narrowed, only the
+// accessible portion is marked.
 func BuiltinMarkWholeBuffer(args []Value, buffer *Buffer) (Value, error) {
 	if len(args) != 0 {
 		return nil, fmt.Errorf("mark-whole-buffer expects 0 arguments, got %d", len(args))
 	}
 
-	content := buffer.String()
-	buffer.SetMark(1)
-	buffer.SetPoint(len(content) + 1)
+	buffer.SetMark(buffer.PointMin())
+	buffer.SetPoint(buffer.PointMax())
 
 	return NewString(""), nil
 }
@@ -25,7 +25,7 @@ func init() {
 	RegisterDocumentation(FunctionDoc{
 		Name:        "mark-whole-buffer",
 		Summary:     "Mark the entire buffer contents",
-		Description: "Marks the entire buffer contents by setting the mark at the beginning of the buffer (position 1) and moving the point to the end of the buffer. This creates a region that encompasses all text in the buffer.",
+		Description: "Marks the entire buffer contents by setting the mark at the beginning of the buffer (position 1) and moving the point to the end of the buffer. This creates a region that encompasses all text in the buffer. When the buffer is narrowed, only the accessible portion is marked.",
 		Category:    "mark",
 		Parameters:  []ParameterDoc{},
 		Examples: []ExampleDoc{
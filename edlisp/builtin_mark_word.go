@@ -14,16 +14,17 @@ func BuiltinMarkWord(args []Value, buffer *Buffer) (Value, error) {
 		return nil, fmt.Errorf("mark-word expects 0 arguments, got %d", len(args))
 	}
 
-	content := buffer.String()
+	content := buffer.String()[:buffer.PointMax()-1]
 	pos := buffer.Point() - 1 // Convert to 0-based
+	minPos := buffer.PointMin() - 1
 
-	if pos < 0 || pos >= len(content) {
+	if pos < minPos || pos >= len(content) {
 		return NewString(""), nil
 	}
 
 	// Find the start of the word (move backward to find non-letter)
 	start := pos
-	for start > 0 && isLetter(content[start-1]) {
+	for start > minPos && isLetter(content[start-1]) {
 		start--
 	}
 
@@ -0,0 +1,103 @@
+package edlisp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BuiltinMessage formats a message and prints it as a line to the output of the evaluation.
+// The format string may contain %s (any value, strings unquoted), %S (any value,
+// strings quoted), %d (a number, rounded towards zero) and %% (a literal percent sign).
+// The output is kept separate from the buffer. Returns the formatted message.
+func BuiltinMessage(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("message expects at least 1 argument, got %d", len(args))
+	}
+
+	if !IsA(args[0], TheStringKind) {
+		return nil, fmt.Errorf("message expects a format string as first argument")
+	}
+
+	format := args[0].(*String).Value
+	values := args[1:]
+
+	var message strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			message.WriteByte(format[i])
+			continue
+		}
+		if i+1 >= len(format) {
+			return nil, fmt.Errorf("message format string ends with %%")
+		}
+		i++
+		directive := format[i]
+		if directive == '%' {
+			message.WriteByte('%')
+			continue
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("message has not enough arguments for format string")
+		}
+		value := values[0]
+		values = values[1:]
+
+		switch directive {
+		case 's':
+			message.WriteString(princString(value))
+		case 'S':
+			message.WriteString(fmt.Sprintf("%v", value))
+		case 'd':
+			if !IsA(value, TheNumberKind) {
+				return nil, fmt.Errorf("message expects a number for %%d")
+			}
+			message.WriteString(fmt.Sprintf("%d", value.(*Number).Int()))
+		default:
+			return nil, fmt.Errorf("message format string has invalid directive %%%c", directive)
+		}
+	}
+
+	if err := buffer.output(message.String() + "\n"); err != nil {
+		return nil, fmt.Errorf("message: %w", err)
+	}
+
+	return NewString(message.String()), nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "message",
+		Summary:     "Print a formatted message",
+		Description: "Formats a message and prints it as a line to the output of the evaluation, which is kept separate from the buffer. The command line prints messages to stderr, the MCP tools return them as an extra text block, and the Go API collects them in an OutputSink. The format string may contain %s (any value, strings unquoted), %S (any value, strings quoted), %d (a number, rounded towards zero) and %% (a literal percent sign). Returns the formatted message.",
+		Category:    "output",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "format",
+				Type:        "string",
+				Description: "The format string",
+				Optional:    false,
+			},
+			{
+				Name:        "args",
+				Type:        "value...",
+				Description: "Values for the directives in the format string",
+				Optional:    true,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Report the version found in a file",
+				Input:       `search-forward "version"; message "found version at line %d" (line-number-at-pos)`,
+				Buffer:      "name = \"demo\"\nversion = \"1.2.3\"",
+				Output:      "Prints \"found version at line 2\"",
+			},
+			{
+				Description: "Print a value",
+				Input:       `message "%s has %d characters" "hello" (length "hello")`,
+				Buffer:      "",
+				Output:      "Prints \"hello has 5 characters\"",
+			},
+		},
+		SeeAlso: []string{"princ"},
+	})
+}
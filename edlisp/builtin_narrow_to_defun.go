@@ -0,0 +1,102 @@
+package edlisp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BuiltinNarrowToDefun narrows the buffer to the top-level definition around point.
+// A definition starts at a line that begins with a non-blank character other
+// than a closing bracket, such as "func", "def" or "class". It extends up to
+// the next such line, with trailing blank lines left outside. Lines starting
+// with a closing bracket belong to the definition above them, so brace-delimited
+// bodies are included.
+func BuiltinNarrowToDefun(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("narrow-to-defun expects 0 arguments, got %d", len(args))
+	}
+
+	content := buffer.String()[:buffer.PointMax()-1]
+	minPos := buffer.PointMin() - 1
+
+	// Find the beginning of the line containing point.
+	pos := buffer.Point() - 1
+	for pos > minPos && content[pos-1] != '\n' {
+		pos--
+	}
+
+	// Walk back to the closest line that starts a definition.
+	start := -1
+	for lineStart := pos; ; {
+		if startsDefun(content[lineStart:]) {
+			start = lineStart
+			break
+		}
+		if lineStart <= minPos {
+			break
+		}
+		lineStart--
+		for lineStart > minPos && content[lineStart-1] != '\n' {
+			lineStart--
+		}
+	}
+	if start == -1 {
+		return nil, fmt.Errorf("narrow-to-defun found no definition around point")
+	}
+
+	// The definition ends where the next one starts.
+	end := len(content)
+	for lineStart := start; ; {
+		next := strings.IndexByte(content[lineStart:], '\n')
+		if next == -1 {
+			break
+		}
+		lineStart += next + 1
+		if startsDefun(content[lineStart:]) {
+			end = lineStart
+			break
+		}
+	}
+
+	// Leave blank lines between definitions outside.
+	for end > start && (content[end-1] == '\n' || content[end-1] == ' ' || content[end-1] == '\t') {
+		end--
+	}
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+
+	buffer.Narrow(start+1, end+1)
+	return NewString(""), nil
+}
+
+// startsDefun reports whether a line beginning with text starts a top-level definition.
+func startsDefun(text string) bool {
+	if text == "" {
+		return false
+	}
+	switch text[0] {
+	case ' ', '\t', '\n', '\r', ')', ']', '}':
+		return false
+	}
+	return true
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "narrow-to-defun",
+		Summary:     "Narrow the buffer to the definition around point",
+		Description: "Narrows the buffer to the top-level definition containing point. A definition starts at a line beginning with a non-blank character other than a closing bracket, such as a line starting with func, def or class, and extends up to the next such line. Lines starting with a closing bracket belong to the definition above them, so brace-delimited bodies are included. Blank lines after the definition are left outside. Signals an error if there is no definition at or before point.",
+		Category:    "narrowing",
+		Parameters:  []ParameterDoc{},
+		Examples: []ExampleDoc{
+			{
+				Description: "Rename a variable only inside one function",
+				Input:       `search-forward "func b"; narrow-to-defun; beginning-of-buffer; re-search-forward "x"; replace-match "y"; widen`,
+				Buffer:      "func a() {\n\tx := 1\n}\n\nfunc b() {\n\tx := 2\n}\n",
+				Output:      "func a() {\n\tx := 1\n}\n\nfunc b() {\n\ty := 2\n}\n",
+			},
+		},
+		SeeAlso: []string{"narrow-to-region", "widen", "save-restriction"},
+	})
+}
@@ -0,0 +1,63 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinNarrowToRegion restricts the buffer to the text between START and END.
+// The positions may be given in any order. Afterwards point-min and point-max
+// return the bounds of the accessible portion, and movement and searches
+// cannot leave it. The text outside is kept and becomes accessible again after widen.
+func BuiltinNarrowToRegion(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("narrow-to-region expects 2 arguments, got %d", len(args))
+	}
+
+	if !IsA(args[0], TheNumberKind) || !IsA(args[1], TheNumberKind) {
+		return nil, fmt.Errorf("narrow-to-region expects number arguments")
+	}
+
+	start := args[0].(*Number).Int()
+	end := args[1].(*Number).Int()
+	buffer.Narrow(start, end)
+
+	return NewString(""), nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "narrow-to-region",
+		Summary:     "Restrict editing and searching to a region",
+		Description: "Restricts the buffer to the text between START and END, which may be given in any order. Afterwards point-min and point-max return the bounds of the accessible portion, and movement, searches and assertions cannot leave it. Point is moved into the accessible portion if necessary. The text outside the region is kept and becomes accessible again after widen. Edits inside the region move its end accordingly.",
+		Category:    "narrowing",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "start",
+				Type:        "number",
+				Description: "One end of the accessible portion",
+				Optional:    false,
+			},
+			{
+				Name:        "end",
+				Type:        "number",
+				Description: "The other end of the accessible portion",
+				Optional:    false,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Replace only inside the second line",
+				Input:       `narrow-to-region 5 8; beginning-of-buffer; re-search-forward "x+"; replace-match "yyy"; widen`,
+				Buffer:      "xxx\nxxx\nxxx",
+				Output:      "xxx\nyyy\nxxx",
+			},
+			{
+				Description: "Narrow to the active region",
+				Input:       `narrow-to-region (mark) (point)`,
+				Buffer:      "Hello world",
+				Output:      "Only the text between mark and point is accessible",
+			},
+		},
+		SeeAlso: []string{"widen", "narrow-to-defun", "save-restriction", "point-min", "point-max"},
+	})
+}
@@ -8,7 +8,8 @@ import (
 //
 // The point-max represents the position just after the last character in the buffer.
 // It is calculated as buffer-size + 1. This is the furthest position the point can
-// be moved to, representing the end of the buffer.
+// be moved to, representing the end of the buffer. When the buffer is narrowed,
+// point-max is the end of the accessible portion instead.
 //
 // For example, in a buffer containing "Hello" (5 characters), point-max would be 6,
 // meaning the point can be positioned after the last character 'o'.
@@ -39,8 +40,7 @@ func BuiltinPointMax(args []Value, buffer *Buffer) (Value, error) {
 		return nil, fmt.Errorf("point-max expects 0 arguments, got %d", len(args))
 	}
 
-	content := buffer.String()
-	return NewNumber(float64(len(content) + 1)), nil
+	return NewNumber(float64(buffer.PointMax())), nil
 }
 
 func init() {
@@ -48,13 +48,13 @@ func init() {
 		Name:        "point-max",
 		Category:    "position",
 		Summary:     "Return the maximum valid position for the point in the buffer",
-		Description: "Returns the position just after the last character in the buffer (buffer-size + 1). This is the furthest position the point can be moved to, representing the end of the buffer. Useful for determining buffer boundaries and validating positions.",
+		Description: "Returns the position just after the last character in the buffer (buffer-size + 1). This is the furthest position the point can be moved to, representing the end of the buffer. When the buffer is narrowed, returns the end of the accessible portion instead. Useful for determining buffer boundaries and validating positions.",
 		Parameters:  []ParameterDoc{},
 		Examples: []ExampleDoc{
 			{Description: "Get point-max of buffer", Input: `point-max`, Buffer: "Hello world test", Output: "17"},
 			{Description: "Get point-max of empty buffer", Input: `point-max`, Buffer: "", Output: "1"},
 			{Description: "Move to end and verify", Input: `end-of-buffer; point; point-max`, Buffer: "Hello", Output: "6; 6"},
 		},
-		SeeAlso: []string{"point-min", "buffer-size", "point", "end-of-buffer"},
+		SeeAlso: []string{"point-min", "buffer-size", "point", "end-of-buffer", "narrow-to-region"},
 	})
 }
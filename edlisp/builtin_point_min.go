@@ -6,9 +6,10 @@ import (
 
 // BuiltinPointMin returns the minimum valid position for the point in the buffer.
 //
-// The point-min is always 1, representing the position just before the first
+// The point-min is 1, representing the position just before the first
 // character in the buffer. This is the earliest position the point can be
-// moved to, regardless of buffer content or size.
+// moved to. When the buffer is narrowed, point-min is the start of the
+// accessible portion instead.
 //
 // In texted's 1-based positioning system:
 // - Position 1 (point-min) is before the first character
@@ -21,7 +22,7 @@ import (
 // - Moving to the beginning of the buffer programmatically
 //
 // Returns:
-//   - number: Always returns 1 (the minimum valid point position)
+//   - number: The minimum valid point position (1 unless narrowed)
 //
 // Examples:
 //
@@ -41,7 +42,7 @@ func BuiltinPointMin(args []Value, buffer *Buffer) (Value, error) {
 		return nil, fmt.Errorf("point-min expects 0 arguments, got %d", len(args))
 	}
 
-	return NewNumber(1), nil
+	return NewNumber(float64(buffer.PointMin())), nil
 }
 
 func init() {
@@ -49,13 +50,13 @@ func init() {
 		Name:        "point-min",
 		Category:    "position",
 		Summary:     "Return the minimum valid position for the point in the buffer",
-		Description: "Returns the minimum valid point position, which is always 1. This represents the position just before the first character in the buffer. The point-min is constant regardless of buffer content or size, providing the start boundary for all position-based operations.",
+		Description: "Returns the minimum valid point position, which is 1 unless the buffer is narrowed. This represents the position just before the first character in the buffer. When the buffer is narrowed, returns the start of the accessible portion instead. The point-min is the start boundary for all position-based operations.",
 		Parameters:  []ParameterDoc{},
 		Examples: []ExampleDoc{
 			{Description: "Get point-min of any buffer", Input: `point-min`, Buffer: "Hello world", Output: "1"},
 			{Description: "Get point-min of empty buffer", Input: `point-min`, Buffer: "", Output: "1"},
 			{Description: "Move to beginning and verify", Input: `beginning-of-buffer; point; point-min`, Buffer: "Hello", Output: "1; 1"},
 		},
-		SeeAlso: []string{"point-max", "buffer-size", "point", "beginning-of-buffer"},
+		SeeAlso: []string{"point-max", "buffer-size", "point", "beginning-of-buffer", "narrow-to-region"},
 	})
 }
@@ -0,0 +1,46 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinPrinc prints a value to the output of the evaluation.
+// Strings are printed without quotes and no newline is added.
+// The output is kept separate from the buffer. Returns the value.
+func BuiltinPrinc(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("princ expects 1 argument, got %d", len(args))
+	}
+
+	if err := buffer.output(princString(args[0])); err != nil {
+		return nil, fmt.Errorf("princ: %w", err)
+	}
+
+	return args[0], nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "princ",
+		Summary:     "Print a value without quoting",
+		Description: "Prints a value to the output of the evaluation, which is kept separate from the buffer. Strings are printed without quotes and no newline is added, so several calls can build up one line. The command line prints to stderr, the MCP tools return the output as an extra text block, and the Go API collects it in an OutputSink. Returns the value.",
+		Category:    "output",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "value",
+				Type:        "value",
+				Description: "The value to print",
+				Optional:    false,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Print the text of the region",
+				Input:       `search-forward "version "; set-mark; end-of-line; princ (buffer-substring (mark) (point))`,
+				Buffer:      "version v1.2.3",
+				Output:      "Prints \"v1.2.3\"",
+			},
+		},
+		SeeAlso: []string{"message"},
+	})
+}
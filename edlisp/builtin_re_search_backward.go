@@ -23,11 +23,8 @@ func BuiltinReSearchBackward(args []Value, buffer *Buffer) (Value, error) {
 	content := buffer.String()
 	endPos := buffer.Point() - 1 // Convert to 0-based
 
-	if endPos > len(content) {
-		endPos = len(content)
-	}
-	if endPos < 0 {
-		return nil, fmt.Errorf("search failed")
+	if endPos > buffer.PointMax()-1 {
+		endPos = buffer.PointMax() - 1
 	}
 
 	re, err := regexp.Compile(str.Value)
@@ -35,14 +32,21 @@ func BuiltinReSearchBackward(args []Value, buffer *Buffer) (Value, error) {
 		return nil, fmt.Errorf("invalid regexp: %v", err)
 	}
 
-	searchArea := content[:endPos]
+	// Only search the accessible portion before point
+	startPos := buffer.PointMin() - 1
+	if startPos > endPos {
+		return nil, ErrSearchFailed
+	}
+	searchArea := content[startPos:endPos]
 	matches := re.FindAllStringIndex(searchArea, -1)
 	if len(matches) == 0 {
-		return nil, fmt.Errorf("search failed")
+		return nil, ErrSearchFailed
 	}
 
 	// Get the last match (rightmost before point)
 	match := matches[len(matches)-1]
+	match[0] += startPos
+	match[1] += startPos
 
 	// Set point to end of found text
 	matchStart := match[0] + 1 // Convert back to 1-based
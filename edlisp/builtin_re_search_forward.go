@@ -20,14 +20,14 @@ func BuiltinReSearchForward(args []Value, buffer *Buffer) (Value, error) {
 	}
 
 	str := args[0].(*String)
-	content := buffer.String()
-	startPos := buffer.Point() - 1 // Convert to 0-based
+	content := buffer.String()[:buffer.PointMax()-1] // Stop at the end of the accessible portion
+	startPos := buffer.Point() - 1                   // Convert to 0-based
 
-	if startPos < 0 {
-		startPos = 0
+	if startPos < buffer.PointMin()-1 {
+		startPos = buffer.PointMin() - 1
 	}
 	if startPos >= len(content) {
-		return nil, fmt.Errorf("search failed")
+		return nil, ErrSearchFailed
 	}
 
 	re, err := regexp.Compile(str.Value)
@@ -37,7 +37,7 @@ func BuiltinReSearchForward(args []Value, buffer *Buffer) (Value, error) {
 
 	match := re.FindStringIndex(content[startPos:])
 	if match == nil {
-		return nil, fmt.Errorf("search failed")
+		return nil, ErrSearchFailed
 	}
 
 	// Set point to end of found text
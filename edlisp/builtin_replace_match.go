@@ -34,9 +34,7 @@ func BuiltinReplaceMatch(args []Value, buffer *Buffer) (Value, error) {
 		return nil, fmt.Errorf("invalid search match positions")
 	}
 
-	newContent := content[:start] + str.Value + content[end:]
-	buffer.content.Reset()
-	buffer.content.WriteString(newContent)
+	buffer.replaceContent(start, end, str.Value)
 
 	// Update point to end of replacement
 	buffer.SetPoint(start + len(str.Value) + 1)
@@ -25,23 +25,15 @@ func BuiltinReplaceRegion(args []Value, buffer *Buffer) (Value, error) {
 		start, end = end, start
 	}
 
-	content := buffer.String()
-	start-- // Convert to 0-based
-	end--   // Convert to 0-based
+	// Only the accessible portion of a narrowed buffer can be changed
+	start = clampPosition(start, buffer.PointMin(), buffer.PointMax()) - 1 // Convert to 0-based
+	end = clampPosition(end, buffer.PointMin(), buffer.PointMax()) - 1     // Convert to 0-based
 
-	if start < 0 {
-		start = 0
-	}
-	if end > len(content) {
-		end = len(content)
-	}
 	if start >= end {
 		return NewString(""), nil
 	}
 
-	newContent := content[:start] + str.Value + content[end:]
-	buffer.content.Reset()
-	buffer.content.WriteString(newContent)
+	buffer.replaceContent(start, end, str.Value)
 
 	// Set point to end of replacement
 	buffer.SetPoint(start + len(str.Value) + 1)
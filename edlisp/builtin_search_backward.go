@@ -22,18 +22,20 @@ func BuiltinSearchBackward(args []Value, buffer *Buffer) (Value, error) {
 	content := buffer.String()
 	endPos := buffer.Point() - 1 // Convert to 0-based
 
-	if endPos > len(content) {
-		endPos = len(content)
+	// Only search the accessible portion before point
+	startPos := buffer.PointMin() - 1
+	if endPos > buffer.PointMax()-1 {
+		endPos = buffer.PointMax() - 1
 	}
-	if endPos < 0 {
-		return nil, fmt.Errorf("search failed")
+	if endPos < startPos {
+		return nil, ErrSearchFailed
 	}
 
-	searchArea := content[:endPos]
-	index := strings.LastIndex(searchArea, str.Value)
+	index := strings.LastIndex(content[startPos:endPos], str.Value)
 	if index == -1 {
-		return nil, fmt.Errorf("search failed")
+		return nil, ErrSearchFailed
 	}
+	index += startPos
 
 	// Set point to end of found text
 	matchStart := index + 1 // Convert back to 1-based
@@ -0,0 +1,56 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinSetBuffer makes the buffer called NAME current.
+// All following forms operate on that buffer until another buffer is made current.
+// Point, mark and narrowing are kept per buffer.
+func BuiltinSetBuffer(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("set-buffer expects 1 argument, got %d", len(args))
+	}
+
+	if !IsA(args[0], TheStringKind) {
+		return nil, fmt.Errorf("set-buffer expects a string argument")
+	}
+
+	name := args[0].(*String).Value
+	state := buffer.State()
+	target := state.GetBuffer(name)
+	if target == nil {
+		return nil, fmt.Errorf("set-buffer: no buffer named %q", name)
+	}
+
+	if err := state.SetCurrent(target); err != nil {
+		return nil, err
+	}
+	return NewString(name), nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "set-buffer",
+		Summary:     "Make a buffer current",
+		Description: "Makes the buffer called NAME current, so that all following forms operate on it until another buffer is made current. Each buffer keeps its own point, mark, narrowing and match data. Signals an error if there is no buffer with that name; use get-buffer-create to create one. Returns the name of the buffer.",
+		Category:    "buffer",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "name",
+				Type:        "string",
+				Description: "The name of the buffer to switch to",
+				Optional:    false,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Build a header in a scratch buffer and copy it into the main buffer",
+				Input:       `set-buffer (get-buffer-create "header"); insert "// Code generated\n"; set-buffer "*main*"; insert-buffer-substring "header"`,
+				Buffer:      "package main",
+				Output:      "// Code generated\npackage main",
+			},
+		},
+		SeeAlso: []string{"get-buffer-create", "current-buffer", "with-temp-buffer"},
+	})
+}
@@ -0,0 +1,51 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinSetBufferEncoding sets the character encoding the current buffer
+// is written back with, converting the file when it is saved.
+// The text of the buffer does not change.
+//
+// Category: buffer
+func BuiltinSetBufferEncoding(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("set-buffer-encoding expects 1 argument, got %d", len(args))
+	}
+
+	if !IsA(args[0], TheStringKind) {
+		return nil, fmt.Errorf("set-buffer-encoding expects a string argument")
+	}
+
+	encoding, err := ParseEncoding(args[0].(*String).Value)
+	if err != nil {
+		return nil, fmt.Errorf("set-buffer-encoding: %w", err)
+	}
+
+	coding := buffer.Coding()
+	coding.Encoding = encoding
+	buffer.SetCoding(coding)
+	return NewString(""), nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "set-buffer-encoding",
+		Category:    "buffer",
+		Summary:     "Set the character encoding the buffer is written with",
+		Description: "Sets the character encoding the buffer is written back with to ENCODING, one of utf-8, utf-16le, utf-16be, iso-8859-1 or windows-1252 (utf8, latin1 and cp1252 are accepted, too). The text of the buffer does not change; it is converted when the file is written, which fails if the new encoding cannot represent a character of it. A byte order mark is kept, and only written for the Unicode encodings.",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "encoding",
+				Type:        "string",
+				Description: "Name of the encoding to write the buffer with",
+				Optional:    false,
+			},
+		},
+		Examples: []ExampleDoc{
+			{Description: "Convert a Latin-1 file to UTF-8", Input: `set-buffer-encoding "utf-8"; buffer-encoding`, Buffer: "Grüße\n", Output: "utf-8"},
+		},
+		SeeAlso: []string{"buffer-encoding", "buffer-eol-type"},
+	})
+}
@@ -0,0 +1,77 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinShellCommandOnRegion runs COMMAND with the region on its standard input.
+// If REPLACE is non-nil, the region is replaced with the output of the command
+// and point ends up after it. Otherwise the buffer is left alone.
+// The command is not run by a shell and its program must be allowed by the
+// environment. Returns the output of the command.
+func BuiltinShellCommandOnRegion(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("shell-command-on-region expects 2 arguments, got %d", len(args))
+	}
+
+	if !IsA(args[0], TheStringKind) {
+		return nil, fmt.Errorf("shell-command-on-region expects a string command")
+	}
+
+	start := clampPosition(buffer.Mark(), buffer.PointMin(), buffer.PointMax())
+	end := clampPosition(buffer.Point(), buffer.PointMin(), buffer.PointMax())
+	if start > end {
+		start, end = end, start
+	}
+
+	output, err := buffer.runCommand(args[0].(*String).Value, buffer.String()[start-1:end-1])
+	if err != nil {
+		return nil, fmt.Errorf("shell-command-on-region: %w", err)
+	}
+
+	if IsTrue(args[1]) {
+		buffer.replaceContent(start-1, end-1, output)
+		buffer.SetMark(start)
+		buffer.SetPoint(start + len(output))
+	}
+
+	return NewString(output), nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "shell-command-on-region",
+		Summary:     "Pipe the region through an external command",
+		Description: "Runs COMMAND with the text between mark and point on its standard input. If REPLACE is non-nil, the region is replaced with the output of the command, mark is set at its beginning and point at its end; otherwise the buffer is left unchanged. Returns the output of the command. COMMAND is split into words like a shell would, but it is not run by a shell, so pipes and redirections are not available. Its program must be explicitly allowed, for example with `texted edit --allow-command sort`; shell commands are disabled by default. A command that runs longer than the timeout (10 seconds by default) or exits with an error fails the script.",
+		Category:    "process",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "command",
+				Type:        "string",
+				Description: "The command line to run",
+				Optional:    false,
+			},
+			{
+				Name:        "replace",
+				Type:        "boolean",
+				Description: "Whether to replace the region with the output",
+				Optional:    false,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Sort the lines of the whole buffer",
+				Input:       `mark-whole-buffer; shell-command-on-region "sort" t`,
+				Buffer:      "banana\napple\ncherry\n",
+				Output:      "apple\nbanana\ncherry\n",
+			},
+			{
+				Description: "Format a Go file with gofmt",
+				Input:       `mark-whole-buffer; shell-command-on-region "gofmt" t`,
+				Buffer:      "package main\nfunc main(){}\n",
+				Output:      "package main\n\nfunc main() {}\n",
+			},
+		},
+		SeeAlso: []string{"shell-command-to-string", "replace-region", "mark-whole-buffer"},
+	})
+}
@@ -0,0 +1,51 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinShellCommandToString runs COMMAND and returns its output as a string.
+// The command gets no input and the buffer is left alone.
+// The command is not run by a shell and its program must be allowed by the environment.
+func BuiltinShellCommandToString(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("shell-command-to-string expects 1 argument, got %d", len(args))
+	}
+
+	if !IsA(args[0], TheStringKind) {
+		return nil, fmt.Errorf("shell-command-to-string expects a string command")
+	}
+
+	output, err := buffer.runCommand(args[0].(*String).Value, "")
+	if err != nil {
+		return nil, fmt.Errorf("shell-command-to-string: %w", err)
+	}
+
+	return NewString(output), nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "shell-command-to-string",
+		Summary:     "Run an external command and return its output",
+		Description: "Runs COMMAND with empty standard input and returns its standard output as a string. The buffer is not modified. COMMAND is split into words like a shell would, but it is not run by a shell, so pipes and redirections are not available. Its program must be explicitly allowed, for example with `texted edit --allow-command date`; shell commands are disabled by default. A command that runs longer than the timeout (10 seconds by default) or exits with an error fails the script.",
+		Category:    "process",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "command",
+				Type:        "string",
+				Description: "The command line to run",
+				Optional:    false,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Insert the current date",
+				Input:       `insert (shell-command-to-string "date +%Y-%m-%d")`,
+				Buffer:      "",
+				Output:      "2024-01-31\n",
+			},
+		},
+		SeeAlso: []string{"shell-command-on-region", "insert"},
+	})
+}
@@ -11,7 +11,7 @@ import (
 // The pattern can be either a literal string or a regular expression.
 // If the pattern is a valid regular expression, it uses regexp matching.
 // If the pattern is not a valid regexp, it falls back to literal string search.
-// Returns the 0-based index of the first match as a number, or the symbol 'nil' if no match is found.
+// Returns the 0-based index of the first match as a number, or nil if no match is found.
 // This function operates on string arguments and does not modify the buffer.
 func BuiltinStringMatch(args []Value, buffer *Buffer) (Value, error) {
 	if len(args) != 2 {
@@ -31,7 +31,7 @@ func BuiltinStringMatch(args []Value, buffer *Buffer) (Value, error) {
 		// If not a valid regex, treat as literal string
 		index := strings.Index(str.Value, pattern.Value)
 		if index == -1 {
-			return NewSymbol("nil"), nil
+			return Nil, nil
 		}
 		return NewNumber(float64(index)), nil
 	}
@@ -39,7 +39,7 @@ func BuiltinStringMatch(args []Value, buffer *Buffer) (Value, error) {
 	// Use regular expression matching
 	match := re.FindStringIndex(str.Value)
 	if match == nil {
-		return NewSymbol("nil"), nil
+		return Nil, nil
 	}
 
 	return NewNumber(float64(match[0])), nil
@@ -49,7 +49,7 @@ func init() {
 	RegisterDocumentation(FunctionDoc{
 		Name:        "string-match",
 		Summary:     "Search for pattern within a string and return match index",
-		Description: "Searches for a pattern within a string and returns the index of the first match. Takes two arguments: a pattern and a target string to search within. The pattern can be either a literal string or a regular expression. If the pattern is a valid regular expression, it uses regexp matching. If the pattern is not a valid regexp, it falls back to literal string search. Returns the 0-based index of the first match as a number, or the symbol 'nil' if no match is found. This function operates on string arguments and does not modify the buffer.",
+		Description: "Searches for a pattern within a string and returns the index of the first match. Takes two arguments: a pattern and a target string to search within. The pattern can be either a literal string or a regular expression. If the pattern is a valid regular expression, it uses regexp matching. If the pattern is not a valid regexp, it falls back to literal string search. Returns the 0-based index of the first match as a number, or nil if no match is found. This function operates on string arguments and does not modify the buffer.",
 		Category:    "string",
 		Parameters: []ParameterDoc{
 			{
@@ -0,0 +1,35 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinWiden removes any restriction set by narrow-to-region or narrow-to-defun.
+// The whole buffer becomes accessible again. Point is left where it is.
+func BuiltinWiden(args []Value, buffer *Buffer) (Value, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("widen expects 0 arguments, got %d", len(args))
+	}
+
+	buffer.Widen()
+	return NewString(""), nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "widen",
+		Summary:     "Make the whole buffer accessible again",
+		Description: "Removes any restriction set by narrow-to-region or narrow-to-defun, so that point-min and point-max again cover the whole buffer. Point is left where it is. Calling widen on a buffer that is not narrowed does nothing.",
+		Category:    "narrowing",
+		Parameters:  []ParameterDoc{},
+		Examples: []ExampleDoc{
+			{
+				Description: "Narrow, edit and widen again",
+				Input:       `narrow-to-region 1 4; end-of-buffer; insert "!"; widen; point-max`,
+				Buffer:      "abc def",
+				Output:      "9",
+			},
+		},
+		SeeAlso: []string{"narrow-to-region", "narrow-to-defun", "save-restriction"},
+	})
+}
@@ -0,0 +1,93 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// BuiltinWriteRegion writes the text between START and END to FILE, replacing its contents.
+// The file is created if it does not exist. The buffer is not modified.
+// The text is written with the encoding, line endings and byte order mark of the buffer.
+// The file is written through the FileSystem of the environment; if there is
+// none, file access is disabled and an error is returned.
+func BuiltinWriteRegion(args []Value, buffer *Buffer) (Value, error) {
+	text, name, err := regionForFile("write-region", args, buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	fsys, err := buffer.fileSystem()
+	if err != nil {
+		return nil, fmt.Errorf("write-region: %w", err)
+	}
+
+	encoded, err := buffer.Coding().Encode(text)
+	if err != nil {
+		return nil, fmt.Errorf("write-region: %w", err)
+	}
+	if err := fsys.WriteFile(name, []byte(encoded)); err != nil {
+		return nil, fmt.Errorf("write-region: %w", err)
+	}
+
+	return NewString(""), nil
+}
+
+// regionForFile validates the START END FILE arguments shared by write-region
+// and append-to-file and returns the text between START and END.
+func regionForFile(fnName string, args []Value, buffer *Buffer) (string, string, error) {
+	if len(args) != 3 {
+		return "", "", fmt.Errorf("%s expects 3 arguments, got %d", fnName, len(args))
+	}
+
+	if !IsA(args[0], TheNumberKind) || !IsA(args[1], TheNumberKind) {
+		return "", "", fmt.Errorf("%s expects number arguments for start and end", fnName)
+	}
+	if !IsA(args[2], TheStringKind) {
+		return "", "", fmt.Errorf("%s expects a string file name as third argument", fnName)
+	}
+
+	start := clampPosition(args[0].(*Number).Int(), buffer.PointMin(), buffer.PointMax())
+	end := clampPosition(args[1].(*Number).Int(), buffer.PointMin(), buffer.PointMax())
+	if start > end {
+		start, end = end, start
+	}
+
+	return buffer.String()[start-1 : end-1], args[2].(*String).Value, nil
+}
+
+func init() {
+	RegisterDocumentation(FunctionDoc{
+		Name:        "write-region",
+		Summary:     "Write part of the buffer to a file",
+		Description: "Writes the text between START and END to FILE, replacing its contents and creating it if necessary. START and END may be given in any order and are clamped to the accessible portion of the buffer. The buffer itself is not modified. The text is written with the encoding, line endings and byte order mark of the buffer (see buffer-encoding and buffer-eol-type); writing fails if the encoding cannot represent the text. File access is disabled by default; it has to be enabled with `texted edit --allow-fs DIR` or the matching MCP server option, and FILE is then a slash-separated path relative to DIR that may not leave it.",
+		Category:    "file",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "start",
+				Type:        "number",
+				Description: "Start position of the text to write",
+				Optional:    false,
+			},
+			{
+				Name:        "end",
+				Type:        "number",
+				Description: "End position of the text to write",
+				Optional:    false,
+			},
+			{
+				Name:        "file",
+				Type:        "string",
+				Description: "Path of the file to write, relative to the allowed directory",
+				Optional:    false,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Save the whole buffer to another file",
+				Input:       `write-region (point-min) (point-max) "copy.txt"`,
+				Buffer:      "Hello world",
+				Output:      "copy.txt contains \"Hello world\"",
+			},
+		},
+		SeeAlso: []string{"append-to-file", "insert-file-contents", "file-exists-p", "buffer-encoding"},
+	})
+}
@@ -0,0 +1,134 @@
+package edlisp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EOLType is a line ending convention, named like in Emacs.
+type EOLType string
+
+const (
+	// EOLUnix ends lines with \n.
+	EOLUnix EOLType = "unix"
+	// EOLDOS ends lines with \r\n.
+	EOLDOS EOLType = "dos"
+	// EOLMac ends lines with \r.
+	EOLMac EOLType = "mac"
+)
+
+// utf8BOM is the byte order mark some editors put at the start of UTF-8 files.
+const utf8BOM = "\xef\xbb\xbf"
+
+// ParseEOLType returns the line ending convention called name.
+func ParseEOLType(name string) (EOLType, error) {
+	switch eol := EOLType(name); eol {
+	case EOLUnix, EOLDOS, EOLMac:
+		return eol, nil
+	default:
+		return "", fmt.Errorf("unknown line ending %q (must be unix, dos or mac)", name)
+	}
+}
+
+// Coding describes how the text of a buffer is stored outside texted.
+// Inside a buffer, text is UTF-8, lines always end with \n and there is no
+// byte order mark, so that builtins such as end-of-line and goto-line work
+// the same on every file.
+type Coding struct {
+	// Encoding is the character encoding. Empty means EncodingUTF8.
+	Encoding Encoding
+
+	// EOL is the line ending convention. Empty means EOLUnix.
+	EOL EOLType
+
+	// BOM is set if the text starts with a byte order mark. It is only
+	// written for the Unicode encodings.
+	BOM bool
+}
+
+// DecodeText detects the coding of text and returns text converted to the
+// buffer conventions. The encoding is guessed by DetectEncoding. Line
+// endings are only converted if they are consistent: text mixing \r\n and
+// \n is taken as unix, so that it is kept as it is.
+func DecodeText(text string) (string, Coding) {
+	return DecodeTextAs(text, "")
+}
+
+// DecodeTextAs is like DecodeText, but reads text in the given encoding
+// instead of detecting it if encoding is not empty.
+func DecodeTextAs(text string, encoding Encoding) (string, Coding) {
+	if encoding == "" {
+		encoding = DetectEncoding(text)
+	}
+	coding := Coding{Encoding: encoding}
+	if bom := encoding.bom(); bom != "" && strings.HasPrefix(text, bom) {
+		coding.BOM = true
+		text = text[len(bom):]
+	}
+	text = encoding.Decode(text)
+
+	crlf := strings.Count(text, "\r\n")
+	lf := strings.Count(text, "\n")
+	switch {
+	case crlf > 0 && crlf == lf:
+		coding.EOL = EOLDOS
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	case lf == 0 && strings.Contains(text, "\r"):
+		coding.EOL = EOLMac
+		text = strings.ReplaceAll(text, "\r", "\n")
+	default:
+		coding.EOL = EOLUnix
+	}
+
+	return text, coding
+}
+
+// NormalizeLineEndings returns text with every \r\n and every \r that does
+// not start one replaced by \n, for text mixing line ending conventions.
+func NormalizeLineEndings(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
+
+// Encode converts buffer text to the coding c, undoing DecodeText.
+// It fails if the encoding cannot represent a character of text.
+func (c Coding) Encode(text string) (string, error) {
+	switch c.EOL {
+	case EOLDOS:
+		text = strings.ReplaceAll(text, "\n", "\r\n")
+	case EOLMac:
+		text = strings.ReplaceAll(text, "\n", "\r")
+	}
+
+	encoding := c.Encoding
+	if encoding == "" {
+		encoding = EncodingUTF8
+	}
+	text, err := encoding.encode(text)
+	if err != nil {
+		return "", err
+	}
+	if c.BOM {
+		text = encoding.bom() + text
+	}
+	return text, nil
+}
+
+// Coding returns how the text of the buffer is stored outside texted.
+func (b *Buffer) Coding() Coding {
+	return b.coding
+}
+
+// Encoding returns the character encoding of the buffer's file.
+func (b *Buffer) Encoding() Encoding {
+	if b.coding.Encoding == "" {
+		return EncodingUTF8
+	}
+	return b.coding.Encoding
+}
+
+// SetCoding sets how the text of the buffer is stored outside texted.
+// It does not change the text of the buffer.
+func (b *Buffer) SetCoding(coding Coding) {
+	b.coding = coding
+}
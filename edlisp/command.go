@@ -0,0 +1,134 @@
+package edlisp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultCommandTimeout limits how long shell builtins may run unless the
+// environment sets its own CommandTimeout.
+const DefaultCommandTimeout = 10 * time.Second
+
+// commandWaitDelay is how long a command that was killed, or that exited,
+// may keep its output open. Processes it started can hold on to stdout
+// long after it is gone, so without a limit they would outlive any timeout.
+const commandWaitDelay = 500 * time.Millisecond
+
+// ErrCommandNotAllowed is returned by shell builtins for programs missing from
+// the AllowedCommands of the environment.
+var ErrCommandNotAllowed = errors.New("command not allowed")
+
+// runCommand runs command with input on stdin and returns its stdout.
+//
+// The command line is split into words like a shell would, but it is not
+// interpreted by a shell: there are no pipes, redirections or variables.
+// The first word must be listed in the AllowedCommands of the environment.
+func (b *Buffer) runCommand(command, input string) (string, error) {
+	words, err := SplitCommand(command)
+	if err != nil {
+		return "", err
+	}
+	if len(words) == 0 {
+		return "", fmt.Errorf("empty command")
+	}
+
+	env := b.State().env
+	if env == nil || !slices.Contains(env.AllowedCommands, words[0]) {
+		return "", fmt.Errorf("%w: %q", ErrCommandNotAllowed, words[0])
+	}
+
+	timeout := env.CommandTimeout
+	if timeout <= 0 {
+		timeout = DefaultCommandTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := commandContext(ctx, words[0], words[1:]...)
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("command %q timed out after %s", words[0], timeout)
+	}
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("command %q failed: %v: %s", words[0], err, message)
+		}
+		return "", fmt.Errorf("command %q failed: %v", words[0], err)
+	}
+
+	return stdout.String(), nil
+}
+
+// commandContext is like exec.CommandContext, but Wait returns at most
+// commandWaitDelay after the command was killed, even if processes it
+// started still hold its output open.
+func commandContext(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = commandWaitDelay
+	return cmd
+}
+
+// SplitCommand splits a command line into words like shell builtins do.
+// Words are separated by unquoted whitespace. Single quotes preserve
+// everything up to the next single quote; inside double quotes and outside
+// quotes a backslash escapes the next character.
+func SplitCommand(command string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case c == '\'':
+			end := strings.IndexByte(command[i+1:], '\'')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated single quote in command")
+			}
+			word.WriteString(command[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '"':
+			i++
+			for ; i < len(command) && command[i] != '"'; i++ {
+				if command[i] == '\\' && i+1 < len(command) {
+					i++
+				}
+				word.WriteByte(command[i])
+			}
+			if i >= len(command) {
+				return nil, fmt.Errorf("unterminated double quote in command")
+			}
+			inWord = true
+		case c == '\\' && i+1 < len(command):
+			i++
+			word.WriteByte(command[i])
+			inWord = true
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
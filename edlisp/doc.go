@@ -1,7 +1,7 @@
 // Package edlisp provides value types for texted's Lisp-like expression system.
 //
 // This package implements the core value types that can be used in texted scripts:
-// symbols, numbers, strings, and lists. Each type implements the Value interface
+// symbols, numbers, strings, lists, booleans and nil. Each type implements the Value interface
 // and has an associated ValueKind for type checking.
 //
 // Example usage:
@@ -0,0 +1,112 @@
+package edlisp
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// EachResult counts the matches EvalEach evaluated a program at.
+type EachResult struct {
+	// Processed counts the matches the program ran at successfully.
+	Processed int
+
+	// Skipped counts the matches whose text earlier runs changed or deleted.
+	Skipped int
+
+	// Failed counts the matches the program failed at.
+	Failed int
+
+	// Errors holds the errors of the failed matches, located by line.
+	Errors []error
+}
+
+// Total returns the number of matches that were found.
+func (r EachResult) Total() int {
+	return r.Processed + r.Skipped + r.Failed
+}
+
+// String summarizes the counts, e.g. "3 matches processed, 1 skipped, 0 failed".
+func (r EachResult) String() string {
+	noun := "matches"
+	if r.Processed == 1 {
+		noun = "match"
+	}
+	return fmt.Sprintf("%d %s processed, %d skipped, %d failed", r.Processed, noun, r.Skipped, r.Failed)
+}
+
+// eachMatch is a match found by EvalEach. Its ends are markers so that it
+// moves along with the edits made at earlier matches.
+type eachMatch struct {
+	start *marker
+	end   *marker
+	text  string
+}
+
+// EvalEach evaluates program once for every match of re in the accessible portion of buffer.
+//
+// All matches are found before the program runs. For each match, point is
+// put at its end, mark at its start, and the match data is set as if
+// re-search-forward had found it, so replace-match replaces the match.
+// Matches move along with the edits made at earlier matches; a match whose
+// text was changed or deleted by then is skipped.
+//
+// If the program fails at a match, the text of buffer is restored to what it
+// was before that run and evaluation continues with the next match. Like
+// with save-restriction, every run starts with the original narrowing.
+func EvalEach(program []Value, env *Environment, buffer *Buffer, re *regexp.Regexp) EachResult {
+	state := buffer.State()
+	previousEnv := state.env
+	state.env = env
+	defer func() { state.env = previousEnv }()
+
+	offset := buffer.PointMin() - 1
+	content := buffer.String()[offset : buffer.PointMax()-1]
+
+	var matches []eachMatch
+	for _, loc := range re.FindAllStringIndex(content, -1) {
+		m := eachMatch{
+			// Text inserted right before a match does not belong to it.
+			start: &marker{pos: offset + loc[0] + 1, advance: true},
+			end:   &marker{pos: offset + loc[1] + 1, advance: loc[0] == loc[1]},
+			text:  content[loc[0]:loc[1]],
+		}
+		buffer.markers = append(buffer.markers, m.start, m.end)
+		matches = append(matches, m)
+	}
+	defer func() {
+		for _, m := range matches {
+			buffer.removeMarker(m.start)
+			buffer.removeMarker(m.end)
+		}
+	}()
+
+	var result EachResult
+	for i, m := range matches {
+		text := buffer.String()
+		if m.start.pos > m.end.pos || text[m.start.pos-1:m.end.pos-1] != m.text {
+			result.Skipped++
+			continue
+		}
+
+		state.SetCurrent(buffer)
+		buffer.SetMark(m.start.pos)
+		buffer.SetPoint(m.end.pos)
+		buffer.lastSearchMatch = m.text
+		buffer.lastSearchStart = m.start.pos
+		buffer.lastSearchEnd = m.end.pos
+
+		if _, err := SpecialFormSaveRestriction(program, env, buffer); err != nil {
+			line := strings.Count(text[:m.start.pos-1], "\n") + 1
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Errorf("match %d at line %d: %w", i+1, line, err))
+			state.SetCurrent(buffer)
+			buffer.replaceChanged(0, buffer.content.Len(), text)
+			continue
+		}
+		result.Processed++
+	}
+
+	state.SetCurrent(buffer)
+	return result
+}
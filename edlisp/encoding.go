@@ -0,0 +1,215 @@
+package edlisp
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding is a character encoding texted can read and write.
+// Buffers always hold UTF-8 text; the encoding is applied when the text is
+// read from and written back to a file.
+type Encoding string
+
+const (
+	// EncodingUTF8 is UTF-8, the default.
+	EncodingUTF8 Encoding = "utf-8"
+	// EncodingUTF16LE is UTF-16 with the least significant byte first.
+	EncodingUTF16LE Encoding = "utf-16le"
+	// EncodingUTF16BE is UTF-16 with the most significant byte first.
+	EncodingUTF16BE Encoding = "utf-16be"
+	// EncodingLatin1 is ISO-8859-1, where every byte is the code point of the same value.
+	EncodingLatin1 Encoding = "iso-8859-1"
+	// EncodingWindows1252 is ISO-8859-1 with printable characters such as
+	// € and curly quotes in place of the control characters 0x80 to 0x9F.
+	EncodingWindows1252 Encoding = "windows-1252"
+)
+
+// encodingAliases maps other common names, with dashes and underscores
+// removed, to the encodings.
+var encodingAliases = map[string]Encoding{
+	"utf8":        EncodingUTF8,
+	"utf16le":     EncodingUTF16LE,
+	"utf16be":     EncodingUTF16BE,
+	"iso88591":    EncodingLatin1,
+	"latin1":      EncodingLatin1,
+	"windows1252": EncodingWindows1252,
+	"cp1252":      EncodingWindows1252,
+}
+
+// ParseEncoding returns the encoding called name. Names are case-insensitive
+// and may be written without dashes, as in utf8; latin1 and cp1252 are
+// accepted as well.
+func ParseEncoding(name string) (Encoding, error) {
+	key := strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(name))
+	if encoding, ok := encodingAliases[key]; ok {
+		return encoding, nil
+	}
+	return "", fmt.Errorf("unknown encoding %q (must be utf-8, utf-16le, utf-16be, iso-8859-1 or windows-1252)", name)
+}
+
+// windows1252 holds the code points of the bytes 0x80 to 0x9F in Windows-1252.
+// The five bytes Windows-1252 leaves undefined map to the control characters
+// of the same value, so that every byte can be read and written back.
+var windows1252 = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡',
+	'ˆ', '‰', 'Š', '‹', 'Œ', '\u008d', 'Ž', '\u008f',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—',
+	'˜', '™', 'š', '›', 'œ', '\u009d', 'ž', 'Ÿ',
+}
+
+// DetectEncoding guesses the encoding of text. A UTF-16 byte order mark
+// decides; otherwise text is UTF-16 if every other byte is zero, as in
+// UTF-16 text of Latin characters, and UTF-8 if it is valid UTF-8. Anything
+// else is taken as Windows-1252 if it uses the bytes 0x80 to 0x9F, which
+// are control characters in ISO-8859-1, and as ISO-8859-1 otherwise.
+func DetectEncoding(text string) Encoding {
+	switch {
+	case strings.HasPrefix(text, EncodingUTF16LE.bom()):
+		return EncodingUTF16LE
+	case strings.HasPrefix(text, EncodingUTF16BE.bom()):
+		return EncodingUTF16BE
+	}
+	if encoding, ok := detectUTF16(text); ok {
+		return encoding
+	}
+	if utf8.ValidString(text) {
+		return EncodingUTF8
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] >= 0x80 && text[i] < 0xa0 {
+			return EncodingWindows1252
+		}
+	}
+	return EncodingLatin1
+}
+
+// detectUTF16 recognizes UTF-16 text without a byte order mark by its zero
+// bytes: at least half of the characters have a zero high byte, and no low
+// byte is zero.
+func detectUTF16(text string) (Encoding, bool) {
+	if len(text) < 2 || len(text)%2 != 0 {
+		return "", false
+	}
+	var zeros [2]int
+	for i := 0; i < len(text); i++ {
+		if text[i] == 0 {
+			zeros[i%2]++
+		}
+	}
+	half := len(text) / 4
+	switch {
+	case zeros[0] == 0 && zeros[1] > 0 && zeros[1] >= half:
+		return EncodingUTF16LE, true
+	case zeros[1] == 0 && zeros[0] > 0 && zeros[0] >= half:
+		return EncodingUTF16BE, true
+	}
+	return "", false
+}
+
+// bom returns the byte order mark of the encoding, or "" if it has none.
+func (e Encoding) bom() string {
+	switch e {
+	case EncodingUTF8:
+		return utf8BOM
+	case EncodingUTF16LE:
+		return "\xff\xfe"
+	case EncodingUTF16BE:
+		return "\xfe\xff"
+	}
+	return ""
+}
+
+// Decode converts text from the encoding to UTF-8, keeping line endings
+// and byte order marks. Bytes that cannot be decoded become U+FFFD; UTF-8
+// text is returned unchanged.
+func (e Encoding) Decode(text string) string {
+	switch e {
+	case EncodingUTF16LE, EncodingUTF16BE:
+		units := make([]uint16, len(text)/2)
+		for i := range units {
+			lo, hi := text[2*i], text[2*i+1]
+			if e == EncodingUTF16BE {
+				lo, hi = hi, lo
+			}
+			units[i] = uint16(hi)<<8 | uint16(lo)
+		}
+		decoded := string(utf16.Decode(units))
+		if len(text)%2 != 0 {
+			decoded += string(utf8.RuneError)
+		}
+		return decoded
+	case EncodingLatin1, EncodingWindows1252:
+		var sb strings.Builder
+		sb.Grow(len(text))
+		for i := 0; i < len(text); i++ {
+			r := rune(text[i])
+			if e == EncodingWindows1252 && r >= 0x80 && r < 0xa0 {
+				r = windows1252[r-0x80]
+			}
+			sb.WriteRune(r)
+		}
+		return sb.String()
+	}
+	return text
+}
+
+// appendRune appends r in the encoding to b. It returns false if the
+// encoding has no representation for r.
+func (e Encoding) appendRune(b []byte, r rune) ([]byte, bool) {
+	switch e {
+	case EncodingUTF16LE, EncodingUTF16BE:
+		for _, unit := range utf16.AppendRune(nil, r) {
+			if e == EncodingUTF16LE {
+				b = append(b, byte(unit), byte(unit>>8))
+			} else {
+				b = append(b, byte(unit>>8), byte(unit))
+			}
+		}
+		return b, true
+	case EncodingLatin1:
+		if r > 0xff {
+			return b, false
+		}
+		return append(b, byte(r)), true
+	case EncodingWindows1252:
+		if r < 0x80 || (r >= 0xa0 && r <= 0xff) {
+			return append(b, byte(r)), true
+		}
+		for i, c := range windows1252 {
+			if c == r {
+				return append(b, byte(0x80+i)), true
+			}
+		}
+		return b, false
+	}
+	return utf8.AppendRune(b, r), true
+}
+
+// encode converts the UTF-8 text to the encoding, reporting the first
+// character it cannot represent with its line number. Lines may end with
+// \n, \r\n or \r.
+func (e Encoding) encode(text string) (string, error) {
+	if e == EncodingUTF8 {
+		return text, nil
+	}
+
+	out := make([]byte, 0, len(text))
+	line := 1
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if r == utf8.RuneError && size == 1 {
+			return "", fmt.Errorf("invalid UTF-8 on line %d cannot be encoded in %s", line, e)
+		}
+		var ok bool
+		if out, ok = e.appendRune(out, r); !ok {
+			return "", fmt.Errorf("cannot encode %q on line %d in %s", r, line, e)
+		}
+		i += size
+		if r == '\n' || r == '\r' && !strings.HasPrefix(text[i:], "\n") {
+			line++
+		}
+	}
+	return string(out), nil
+}
@@ -33,6 +33,14 @@ func Equal(a, b Value) bool {
 		bSym := b.(*Symbol)
 		return aSym.Name == bSym.Name
 
+	case IsA(a, TheBooleanKind):
+		aBool := a.(*Boolean)
+		bBool := b.(*Boolean)
+		return aBool.Value == bBool.Value
+
+	case IsA(a, TheNullKind):
+		return true
+
 	case IsA(a, TheListKind):
 		aList := a.(*List)
 		bList := b.(*List)
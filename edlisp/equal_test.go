@@ -90,6 +90,52 @@ func TestEqual_Symbols(t *testing.T) {
 	}
 }
 
+func TestEqual_Booleans(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     Value
+		expected bool
+	}{
+		{"t and t", T, NewBoolean(true), true},
+		{"t and false", T, NewBoolean(false), false},
+		{"nil and nil", Nil, &Null{}, true},
+		{"nil and false", Nil, NewBoolean(false), false},
+		{"nil and symbol nil", Nil, NewSymbol("nil"), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := Equal(test.a, test.b)
+			if result != test.expected {
+				t.Errorf("Equal(%v, %v) = %v, want %v", test.a, test.b, result, test.expected)
+			}
+		})
+	}
+}
+
+func TestIsTrue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    Value
+		expected bool
+	}{
+		{"t", T, true},
+		{"nil", Nil, false},
+		{"false", NewBoolean(false), false},
+		{"go nil", nil, false},
+		{"empty string", NewString(""), true},
+		{"zero", NewNumber(0), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := IsTrue(test.value); result != test.expected {
+				t.Errorf("IsTrue(%v) = %v, want %v", test.value, result, test.expected)
+			}
+		})
+	}
+}
+
 func TestEqual_Lists(t *testing.T) {
 	tests := []struct {
 		name     string
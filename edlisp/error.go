@@ -1,8 +1,18 @@
 package edlisp
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrSearchFailed is returned by search functions when the text cannot be found.
+	ErrSearchFailed = errors.New("search failed")
+
+	// ErrUndefinedFunction is returned when a script calls a function that does not exist.
+	ErrUndefinedFunction = errors.New("undefined-function")
 )
 
 // ExecutionError captures the full execution state at the time of an error occurrence.
@@ -74,6 +84,15 @@ func (e *ExecutionError) Unwrap() error {
 	return e.OriginalError
 }
 
+// Position returns the line and column of point when the error occurred.
+// Both start at 1, and columns count characters.
+func (e *ExecutionError) Position() (line, column int) {
+	text := e.BufferContents[:max(0, min(e.Point-1, len(e.BufferContents)))]
+	line = strings.Count(text, "\n") + 1
+	column = utf8.RuneCountInString(text[strings.LastIndexByte(text, '\n')+1:]) + 1
+	return line, column
+}
+
 // NewExecutionError creates a new ExecutionError with the current execution state.
 func NewExecutionError(originalError error, program []Value, instructionIndex int, currentInstruction Value, buffer *Buffer, env *Environment) *ExecutionError {
 	return &ExecutionError{
@@ -90,3 +109,43 @@ func NewExecutionError(originalError error, program []Value, instructionIndex in
 		Environment:        env,
 	}
 }
+
+// AssertionFailed is returned when a script assertion does not hold or when
+// a script signals an error explicitly through the error builtin.
+type AssertionFailed struct {
+	// Function is the name of the builtin that raised the error
+	Function string
+
+	// Message describes the intent that was violated
+	Message string
+}
+
+// Error implements the error interface.
+func (e *AssertionFailed) Error() string {
+	return fmt.Sprintf("assertion failed: %s", e.Message)
+}
+
+// NewAssertionFailed creates a new AssertionFailed error for the given function.
+func NewAssertionFailed(function, message string) *AssertionFailed {
+	return &AssertionFailed{
+		Function: function,
+		Message:  message,
+	}
+}
+
+// ErrorKind classifies an error by the name scripts use to refer to it,
+// for example in condition-case handlers.
+// Errors without a more specific kind are reported as "error".
+func ErrorKind(err error) string {
+	var assertionErr *AssertionFailed
+	switch {
+	case errors.As(err, &assertionErr):
+		return "assertion-failed"
+	case errors.Is(err, ErrSearchFailed):
+		return "search-failed"
+	case errors.Is(err, ErrUndefinedFunction):
+		return "undefined-function"
+	default:
+		return "error"
+	}
+}
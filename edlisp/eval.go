@@ -3,6 +3,7 @@ package edlisp
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 // BuiltinFn represents a built-in function that can be called from texted scripts.
@@ -22,6 +23,37 @@ type TraceCallback func(ctx *TraceContext)
 type Environment struct {
 	// Functions maps function names to their implementations
 	Functions map[string]BuiltinFn
+
+	// FileSystem is the file access available to file builtins such as
+	// insert-file-contents. A nil FileSystem disables file access.
+	FileSystem FileSystem
+
+	// AllowedCommands lists the programs shell builtins such as
+	// shell-command-on-region may run. An empty list disables them.
+	AllowedCommands []string
+
+	// CommandTimeout limits how long a shell builtin may run.
+	// Zero means DefaultCommandTimeout.
+	CommandTimeout time.Duration
+
+	// Output receives the text printed by message and princ.
+	// A nil Output discards it.
+	Output OutputSink
+
+	// LoadPath lists the directories load searches for script files.
+	// An empty LoadPath disables load.
+	LoadPath []string
+
+	// ParseScript parses the script files read by load.
+	// Nil disables load; texted.Options sets it to parser.ParseScript.
+	ParseScript ScriptParser
+
+	// Args holds the values of the script arguments used by arg and $NAME.
+	Args map[string]string
+
+	// docs holds the documentation of the functions and special forms
+	// available in this environment.
+	docs map[string]FunctionDoc
 }
 
 // Buffer represents a text buffer for editing operations.
@@ -32,6 +64,11 @@ type Buffer struct {
 	lastSearchMatch string
 	lastSearchStart int
 	lastSearchEnd   int
+	markers         []*marker
+	restriction     *restriction
+	name            string
+	state           *State
+	coding          Coding
 }
 
 // NewBuffer creates a new buffer with the given initial content.
@@ -59,7 +96,11 @@ func (b *Buffer) Mark() int {
 }
 
 // SetPoint sets the cursor position.
+// In a narrowed buffer the position is clamped to the accessible portion.
 func (b *Buffer) SetPoint(pos int) {
+	if b.restriction != nil {
+		pos = clampPosition(pos, b.PointMin(), b.PointMax())
+	}
 	b.point = pos
 }
 
@@ -70,21 +111,29 @@ func (b *Buffer) SetMark(pos int) {
 
 // Insert inserts text at the current point.
 func (b *Buffer) Insert(text string) {
-	content := b.content.String()
-	if b.point <= 1 {
-		b.content.Reset()
-		b.content.WriteString(text)
-		b.content.WriteString(content)
-	} else if b.point > len(content)+1 {
-		b.content.WriteString(text)
-	} else {
-		newContent := content[:b.point-1] + text + content[b.point-1:]
-		b.content.Reset()
-		b.content.WriteString(newContent)
+	pos := b.point - 1
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > b.content.Len() {
+		pos = b.content.Len()
 	}
+	b.replaceContent(pos, pos, text)
 	b.point += len(text)
 }
 
+// replaceContent replaces the bytes between the 0-based offsets start and end
+// with text. All edits of the buffer content go through this method so that
+// markers stay attached to the text they point at.
+func (b *Buffer) replaceContent(start, end int, text string) {
+	content := b.content.String()
+	b.content.Reset()
+	b.content.WriteString(content[:start])
+	b.content.WriteString(text)
+	b.content.WriteString(content[end:])
+	b.adjustMarkers(start, end, len(text))
+}
+
 // Eval executes a texted program in the given environment.
 func Eval(program []Value, env *Environment, buffer *Buffer) (Value, error) {
 	return EvalWithTrace(program, env, buffer, nil)
@@ -94,16 +143,22 @@ func Eval(program []Value, env *Environment, buffer *Buffer) (Value, error) {
 func EvalWithTrace(program []Value, env *Environment, buffer *Buffer, traceCallback TraceCallback) (Value, error) {
 	var result Value = NewString("")
 
+	// Builtins reach the environment through the state of their buffer.
+	state := buffer.State()
+	previousEnv := state.env
+	state.env = env
+	defer func() { state.env = previousEnv }()
+
 	for i, expr := range program {
 		val, err := evalExpression(expr, env, buffer)
 		if err != nil {
-			return nil, NewExecutionError(err, program, i, expr, buffer, env)
+			return nil, NewExecutionError(err, program, i, expr, buffer.current(), env)
 		}
 		result = val
 
 		if traceCallback != nil {
 			traceCallback(&TraceContext{
-				Buffer:      buffer,
+				Buffer:      buffer.current(),
 				Environment: env,
 				Instruction: expr,
 			})
@@ -120,6 +175,8 @@ func evalExpression(expr Value, env *Environment, buffer *Buffer) (Value, error)
 		return expr, nil
 	case IsA(expr, TheNumberKind):
 		return expr, nil
+	case IsA(expr, TheBooleanKind), IsA(expr, TheNullKind):
+		return expr, nil
 	case IsA(expr, TheListKind):
 		list := expr.(*List)
 		if list.Len() == 0 {
@@ -134,9 +191,17 @@ func evalExpression(expr Value, env *Environment, buffer *Buffer) (Value, error)
 		symbol := firstElem.(*Symbol)
 		fnName := symbol.Name
 
+		// Forms always operate on the current buffer, which an earlier
+		// set-buffer may have changed.
+		buffer = buffer.current()
+
+		if form, isSpecial := specialForms[fnName]; isSpecial {
+			return form(list.Elements[1:], env, buffer)
+		}
+
 		fn, exists := env.Functions[fnName]
 		if !exists {
-			return nil, fmt.Errorf("undefined-function %q", fnName)
+			return nil, fmt.Errorf("%w %q", ErrUndefinedFunction, fnName)
 		}
 
 		args := make([]Value, list.Len()-1)
@@ -148,7 +213,8 @@ func evalExpression(expr Value, env *Environment, buffer *Buffer) (Value, error)
 			args[i-1] = evaluatedArg
 		}
 
-		result, err := fn(args, buffer)
+		// Evaluating the arguments may have switched buffers, too.
+		result, err := fn(args, buffer.current())
 		if err != nil {
 			return nil, err
 		}
@@ -192,6 +258,9 @@ func NewDefaultEnvironment() *Environment {
 	env.Functions["beginning-of-line"] = BuiltinBeginningOfLine
 	env.Functions["end-of-line"] = BuiltinEndOfLine
 	env.Functions["buffer-size"] = BuiltinBufferSize
+	env.Functions["buffer-eol-type"] = BuiltinBufferEOLType
+	env.Functions["buffer-encoding"] = BuiltinBufferEncoding
+	env.Functions["set-buffer-encoding"] = BuiltinSetBufferEncoding
 	env.Functions["point-max"] = BuiltinPointMax
 	env.Functions["point-min"] = BuiltinPointMin
 	env.Functions["current-column"] = BuiltinCurrentColumn
@@ -215,6 +284,43 @@ func NewDefaultEnvironment() *Environment {
 	env.Functions["backward-kill-word"] = BuiltinBackwardKillWord
 	env.Functions["re-search-backward"] = BuiltinReSearchBackward
 	env.Functions["replace-regexp-in-string"] = BuiltinReplaceRegexpInString
+	env.Functions["assert"] = BuiltinAssert
+	env.Functions["error"] = BuiltinError
+	env.Functions["assert-looking-at"] = BuiltinAssertLookingAt
+	env.Functions["assert-looking-back"] = BuiltinAssertLookingBack
+	env.Functions["assert-line-number"] = BuiltinAssertLineNumber
+	env.Functions["assert-region-text"] = BuiltinAssertRegionText
+	env.Functions["assert-match-count"] = BuiltinAssertMatchCount
+	env.Functions["narrow-to-region"] = BuiltinNarrowToRegion
+	env.Functions["narrow-to-defun"] = BuiltinNarrowToDefun
+	env.Functions["widen"] = BuiltinWiden
+	env.Functions["get-buffer-create"] = BuiltinGetBufferCreate
+	env.Functions["set-buffer"] = BuiltinSetBuffer
+	env.Functions["current-buffer"] = BuiltinCurrentBuffer
+	env.Functions["buffer-string"] = BuiltinBufferString
+	env.Functions["insert-buffer-substring"] = BuiltinInsertBufferSubstring
+	env.Functions["insert-file-contents"] = BuiltinInsertFileContents
+	env.Functions["write-region"] = BuiltinWriteRegion
+	env.Functions["append-to-file"] = BuiltinAppendToFile
+	env.Functions["file-exists-p"] = BuiltinFileExistsP
+	env.Functions["load"] = BuiltinLoad
+	env.Functions["arg"] = BuiltinArg
+	env.Functions["shell-command-on-region"] = BuiltinShellCommandOnRegion
+	env.Functions["shell-command-to-string"] = BuiltinShellCommandToString
+	env.Functions["message"] = BuiltinMessage
+	env.Functions["princ"] = BuiltinPrinc
+
+	// Document the builtins and special forms from their registered documentation
+	for name := range env.Functions {
+		if doc, exists := GetDocumentation(name); exists {
+			env.setDocumentation(doc)
+		}
+	}
+	for name := range specialForms {
+		if doc, exists := GetDocumentation(name); exists {
+			env.setDocumentation(doc)
+		}
+	}
 
 	return env
 }
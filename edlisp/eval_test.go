@@ -1,8 +1,14 @@
 package edlisp
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestBuffer(t *testing.T) {
@@ -179,3 +185,578 @@ func TestEvalNestedFunctionCalls(t *testing.T) {
 		t.Errorf("Expected 2, got %f", num.Value)
 	}
 }
+
+func TestEvalAssertionFailed(t *testing.T) {
+	env := NewDefaultEnvironment()
+	buffer := NewBuffer("package main")
+
+	program := []Value{
+		NewList(NewSymbol("assert-looking-at"), NewString("func"), NewString("expected a function")),
+	}
+	_, err := Eval(program, env, buffer)
+	if err == nil {
+		t.Fatal("Expected assertion to fail")
+	}
+
+	var assertionErr *AssertionFailed
+	if !errors.As(err, &assertionErr) {
+		t.Fatalf("Expected AssertionFailed, got %T: %v", err, err)
+	}
+	if assertionErr.Function != "assert-looking-at" {
+		t.Errorf("Expected function 'assert-looking-at', got %q", assertionErr.Function)
+	}
+	if assertionErr.Message != "expected a function" {
+		t.Errorf("Expected message 'expected a function', got %q", assertionErr.Message)
+	}
+}
+
+func TestErrorKind(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"search failed", ErrSearchFailed, "search-failed"},
+		{"assertion failed", NewAssertionFailed("assert", "message"), "assertion-failed"},
+		{"undefined function", fmt.Errorf("%w %q", ErrUndefinedFunction, "foo"), "undefined-function"},
+		{"wrapped", NewExecutionError(ErrSearchFailed, nil, 0, nil, NewBuffer(""), nil), "search-failed"},
+		{"other", errors.New("insert expects 1 argument, got 0"), "error"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if kind := ErrorKind(test.err); kind != test.expected {
+				t.Errorf("ErrorKind(%v) = %q, want %q", test.err, kind, test.expected)
+			}
+		})
+	}
+}
+
+func TestBufferMarkersFollowEdits(t *testing.T) {
+	buffer := NewBuffer("hello world")
+
+	before := buffer.addMarker(1)
+	inside := buffer.addMarker(8)
+	after := buffer.addMarker(12)
+
+	// Replace "o wo" (offsets 4-8) with "!"
+	buffer.replaceContent(4, 8, "!")
+	if buffer.String() != "hell!rld" {
+		t.Fatalf("Expected 'hell!rld', got %q", buffer.String())
+	}
+
+	if before.pos != 1 {
+		t.Errorf("Expected marker before edit to stay at 1, got %d", before.pos)
+	}
+	if inside.pos != 5 {
+		t.Errorf("Expected marker inside edit to collapse to 5, got %d", inside.pos)
+	}
+	if after.pos != 9 {
+		t.Errorf("Expected marker after edit to move to 9, got %d", after.pos)
+	}
+
+	buffer.removeMarker(after)
+	buffer.SetPoint(1)
+	buffer.Insert(">> ")
+	if before.pos != 1 {
+		t.Errorf("Expected marker at insertion point to stay at 1, got %d", before.pos)
+	}
+	if after.pos != 9 {
+		t.Errorf("Expected removed marker to stay at 9, got %d", after.pos)
+	}
+}
+
+func TestBufferNarrow(t *testing.T) {
+	buffer := NewBuffer("alpha beta gamma")
+	buffer.SetPoint(2)
+
+	buffer.Narrow(11, 7)
+	if buffer.PointMin() != 7 || buffer.PointMax() != 11 {
+		t.Fatalf("Expected accessible portion 7-11, got %d-%d", buffer.PointMin(), buffer.PointMax())
+	}
+	if buffer.Point() != 7 {
+		t.Errorf("Expected point to move into the accessible portion, got %d", buffer.Point())
+	}
+
+	buffer.SetPoint(11)
+	buffer.Insert("!")
+	if buffer.PointMax() != 12 {
+		t.Errorf("Expected insertion at point-max to extend the accessible portion, got %d", buffer.PointMax())
+	}
+	if buffer.String() != "alpha beta! gamma" {
+		t.Errorf("Expected String to return the whole buffer, got %q", buffer.String())
+	}
+
+	buffer.Widen()
+	if buffer.IsNarrowed() || buffer.PointMin() != 1 || buffer.PointMax() != 18 {
+		t.Errorf("Expected widen to restore 1-18, got %d-%d", buffer.PointMin(), buffer.PointMax())
+	}
+}
+
+func TestStateNamedBuffers(t *testing.T) {
+	main := NewBuffer("main")
+	state := NewState(main)
+	if err := state.AddBuffer("template", NewBuffer("Hello, ")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := state.AddBuffer("template", NewBuffer("")); err == nil {
+		t.Errorf("Expected an error when adding a buffer with a duplicate name")
+	}
+
+	program := []Value{
+		NewList(NewSymbol("insert-buffer-substring"), NewString("template")),
+		NewList(NewSymbol("set-buffer"), NewList(NewSymbol("get-buffer-create"), NewString("log"))),
+		NewList(NewSymbol("insert"), NewString("done")),
+	}
+	if _, err := Eval(program, NewDefaultEnvironment(), main); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if state.Current().Name() != "log" {
+		t.Errorf("Expected current buffer 'log', got %q", state.Current().Name())
+	}
+
+	expected := map[string]string{
+		MainBufferName: "Hello, main",
+		"template":     "Hello, ",
+		"log":          "done",
+	}
+	buffers := state.Buffers()
+	if len(buffers) != len(expected) {
+		t.Fatalf("Expected %d buffers, got %d", len(expected), len(buffers))
+	}
+	for _, buffer := range buffers {
+		if buffer.String() != expected[buffer.Name()] {
+			t.Errorf("Expected buffer %q to contain %q, got %q", buffer.Name(), expected[buffer.Name()], buffer.String())
+		}
+	}
+}
+
+func TestFileBuiltins(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "header.txt"), []byte("// header\n"), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	fsys, err := DirFS(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	env := NewDefaultEnvironment()
+	env.FileSystem = fsys
+
+	buffer := NewBuffer("body")
+	program := []Value{
+		NewList(NewSymbol("insert-file-contents"), NewString("header.txt")),
+		NewList(NewSymbol("write-region"), NewNumber(1), NewNumber(11), NewString("out.txt")),
+		NewList(NewSymbol("append-to-file"), NewNumber(11), NewNumber(15), NewString("out.txt")),
+		NewList(NewSymbol("file-exists-p"), NewString("missing.txt")),
+	}
+	result, err := Eval(program, env, buffer)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != Nil {
+		t.Errorf("Expected file-exists-p to return nil for a missing file, got %v", result)
+	}
+	if buffer.String() != "// header\nbody" || buffer.Point() != 1 {
+		t.Errorf("Expected header inserted before point, got %q with point %d", buffer.String(), buffer.Point())
+	}
+
+	written, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(written) != "// header\nbody" {
+		t.Errorf("Expected out.txt to contain the buffer, got %q", written)
+	}
+
+	escape := []Value{NewList(NewSymbol("insert-file-contents"), NewString("../outside.txt"))}
+	if _, err := Eval(escape, env, NewBuffer("")); err == nil {
+		t.Errorf("Expected an error when reading outside the allowed directory")
+	}
+
+	disabled := []Value{NewList(NewSymbol("file-exists-p"), NewString("header.txt"))}
+	_, err = Eval(disabled, NewDefaultEnvironment(), NewBuffer(""))
+	if !errors.Is(err, ErrFileAccessDisabled) {
+		t.Errorf("Expected ErrFileAccessDisabled without a FileSystem, got %v", err)
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		command  string
+		expected []string
+	}{
+		{"sort", []string{"sort"}},
+		{"sort -r  -u", []string{"sort", "-r", "-u"}},
+		{`tr 'a-z' "A-Z"`, []string{"tr", "a-z", "A-Z"}},
+		{`printf "say \"hi\""`, []string{"printf", `say "hi"`}},
+		{`echo a\ b ''`, []string{"echo", "a b", ""}},
+	}
+
+	for _, test := range tests {
+		words, err := SplitCommand(test.command)
+		if err != nil {
+			t.Errorf("SplitCommand(%q) returned error: %v", test.command, err)
+			continue
+		}
+		if strings.Join(words, "|") != strings.Join(test.expected, "|") || len(words) != len(test.expected) {
+			t.Errorf("SplitCommand(%q) = %q, want %q", test.command, words, test.expected)
+		}
+	}
+
+	if _, err := SplitCommand(`echo "unterminated`); err == nil {
+		t.Errorf("Expected an error for an unterminated quote")
+	}
+}
+
+func TestShellCommandOnRegion(t *testing.T) {
+	env := NewDefaultEnvironment()
+	env.AllowedCommands = []string{"tr", "sleep"}
+
+	buffer := NewBuffer("keep upcase keep")
+	buffer.SetMark(6)
+	buffer.SetPoint(12)
+	program := []Value{NewList(NewSymbol("shell-command-on-region"), NewString("tr a-z A-Z"), T)}
+	if _, err := Eval(program, env, buffer); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if buffer.String() != "keep UPCASE keep" {
+		t.Errorf("Expected region to be replaced, got %q", buffer.String())
+	}
+
+	denied := []Value{NewList(NewSymbol("shell-command-to-string"), NewString("rm -rf /"))}
+	if _, err := Eval(denied, env, NewBuffer("")); !errors.Is(err, ErrCommandNotAllowed) {
+		t.Errorf("Expected ErrCommandNotAllowed, got %v", err)
+	}
+
+	env.CommandTimeout = 50 * time.Millisecond
+	slow := []Value{NewList(NewSymbol("shell-command-to-string"), NewString("sleep 5"))}
+	if _, err := Eval(slow, env, NewBuffer("")); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected a timeout error, got %v", err)
+	}
+
+	// A process started by the command keeps stdout open after it is killed
+	env.AllowedCommands = append(env.AllowedCommands, "sh")
+	started := time.Now()
+	orphan := []Value{NewList(NewSymbol("shell-command-to-string"), NewString("sh -c 'sleep 5 & sleep 5'"))}
+	if _, err := Eval(orphan, env, NewBuffer("")); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected a timeout error, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Errorf("Expected the command to stop soon after the timeout, took %s", elapsed)
+	}
+}
+
+func TestDefaultEnvironmentDocumentation(t *testing.T) {
+	env := NewDefaultEnvironment()
+
+	for name := range env.Functions {
+		if _, exists := env.Documentation(name); !exists {
+			t.Errorf("builtin %q has no documentation", name)
+		}
+	}
+	for name := range specialForms {
+		if _, exists := env.Documentation(name); !exists {
+			t.Errorf("special form %q has no documentation", name)
+		}
+	}
+	for _, doc := range GetAllDocumentation() {
+		if _, exists := env.Functions[doc.Name]; !exists && !IsSpecialForm(doc.Name) {
+			t.Errorf("documentation for %q has no builtin in the default environment", doc.Name)
+		}
+	}
+}
+
+func TestEnvironmentRegister(t *testing.T) {
+	env := NewDefaultEnvironment()
+	doc := FunctionDoc{
+		Name:     "surround",
+		Summary:  "Insert text before and after point",
+		Category: "custom",
+		Parameters: []ParameterDoc{
+			{Name: "before", Type: "string"},
+			{Name: "after", Type: "string", Optional: true},
+		},
+	}
+	err := env.Register(doc, func(args []Value, buffer *Buffer) (Value, error) {
+		buffer.Insert(args[0].(*String).Value)
+		if len(args) == 2 {
+			pos := buffer.Point()
+			buffer.Insert(args[1].(*String).Value)
+			buffer.SetPoint(pos)
+		}
+		return T, nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := env.Register(doc, BuiltinInsert); err == nil {
+		t.Errorf("Expected an error when registering %q twice", doc.Name)
+	}
+	if got, exists := env.Documentation("surround"); !exists || got.Summary != doc.Summary {
+		t.Errorf("Expected documentation for surround, got %v", got)
+	}
+	if categories := env.DocumentationByCategory("custom"); len(categories) != 1 {
+		t.Errorf("Expected one function in category custom, got %d", len(categories))
+	}
+	if _, exists := NewDefaultEnvironment().Documentation("surround"); exists {
+		t.Errorf("Expected registration to be scoped to one environment")
+	}
+
+	buffer := NewBuffer("")
+	program := []Value{NewList(NewSymbol("surround"), NewString("("), NewString(")"))}
+	if _, err := Eval(program, env, buffer); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if buffer.String() != "()" || buffer.Point() != 2 {
+		t.Errorf("Expected \"()\" with point 2, got %q with point %d", buffer.String(), buffer.Point())
+	}
+
+	tests := []struct {
+		args     []Value
+		expected string
+	}{
+		{[]Value{}, "surround expects 1 to 2 arguments, got 0"},
+		{[]Value{NewString("a"), NewString("b"), NewString("c")}, "surround expects 1 to 2 arguments, got 3"},
+		{[]Value{NewNumber(1)}, "surround expects a string for before"},
+	}
+	for _, test := range tests {
+		program := []Value{NewList(append([]Value{NewSymbol("surround")}, test.args...)...)}
+		_, err := Eval(program, env, NewBuffer(""))
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("Expected error %q, got %v", test.expected, err)
+		}
+	}
+}
+
+func TestPlugin(t *testing.T) {
+	dir := t.TempDir()
+	script := `#!/bin/sh
+if [ "$1" = "--describe" ]; then
+  echo '{"summary": "Shouts the first word", "parameters": [{"name": "word", "type": "string"}]}'
+  exit 0
+fi
+cat > "$(dirname "$0")/request.json"
+echo '{"value": [1, "two", true], "buffer": "HELLO world", "point": 6}'
+`
+	path := filepath.Join(dir, PluginPrefix+"shout")
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, PluginPrefix+"not-executable"), []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	found := FindPlugins(dir)
+	if len(found) != 1 || found[0] != path {
+		t.Fatalf("Expected to find only %s, got %v", path, found)
+	}
+
+	plugin, err := DescribePlugin(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if plugin.Doc.Name != "shout" || plugin.Doc.Category != "plugin" || len(plugin.Doc.Parameters) != 1 {
+		t.Errorf("Unexpected documentation: %+v", plugin.Doc)
+	}
+
+	env := NewDefaultEnvironment()
+	if err := env.RegisterPlugin(plugin); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, exists := env.Documentation("shout"); !exists {
+		t.Error("Expected the plugin to be documented")
+	}
+
+	buffer := NewBuffer("hello world")
+	buffer.SetMark(1)
+	buffer.SetPoint(6)
+	program := []Value{NewList(NewSymbol("shout"), NewString("hello"))}
+	result, err := Eval(program, env, buffer)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !Equal(result, NewList(NewNumber(1), NewString("two"), T)) {
+		t.Errorf("Unexpected result: %v", result)
+	}
+	if buffer.String() != "HELLO world" || buffer.Point() != 6 || buffer.Mark() != 1 {
+		t.Errorf("Unexpected buffer %q with point %d and mark %d", buffer.String(), buffer.Point(), buffer.Mark())
+	}
+
+	request, err := os.ReadFile(filepath.Join(dir, "request.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"args":["hello"],"buffer":"hello world","point":6,"mark":1,"point_min":1,"point_max":12,"region":"hello"}`
+	if strings.TrimSpace(string(request)) != expected {
+		t.Errorf("Expected request %s, got %s", expected, request)
+	}
+
+	if _, err := Eval([]Value{NewList(NewSymbol("shout"))}, env, NewBuffer("")); err == nil {
+		t.Error("Expected an argument count error")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"lib/greet.txt":            "insert hello\nload lib/missing-function.txt",
+		"lib/missing-function.txt": "insert !\nno-such-function",
+		"loop.txt":                 "load loop.txt",
+	}
+	for name, source := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Each line calls the function named by its first word with the other words as strings.
+	parse := func(name, source string) (*Script, error) {
+		script := &Script{Name: name}
+		for i, line := range strings.Split(source, "\n") {
+			words := strings.Fields(line)
+			call := NewList(NewSymbol(words[0]))
+			for _, word := range words[1:] {
+				call.Elements = append(call.Elements, NewString(word))
+			}
+			script.Program = append(script.Program, call)
+			script.Lines = append(script.Lines, i+1)
+		}
+		return script, nil
+	}
+
+	env := NewDefaultEnvironment()
+	program := []Value{NewList(NewSymbol("load"), NewString("lib/greet.txt"))}
+	if _, err := Eval(program, env, NewBuffer("")); !errors.Is(err, ErrLoadDisabled) {
+		t.Errorf("Expected ErrLoadDisabled, got %v", err)
+	}
+
+	env.LoadPath = []string{filepath.Join(dir, "missing"), dir}
+	env.ParseScript = parse
+	buffer := NewBuffer("")
+	_, err := Eval(program, env, buffer)
+	if err == nil || !strings.Contains(err.Error(), `lib/greet.txt:2: load: lib/missing-function.txt:2: undefined-function "no-such-function"`) {
+		t.Errorf("Expected the error to be located in the loaded files, got %v", err)
+	}
+	if !errors.Is(err, ErrUndefinedFunction) {
+		t.Errorf("Expected the error to wrap ErrUndefinedFunction, got %v", err)
+	}
+	if buffer.String() != "hello!" {
+		t.Errorf("Expected both files to be evaluated in the buffer, got %q", buffer.String())
+	}
+
+	loop := []Value{NewList(NewSymbol("load"), NewString("loop.txt"))}
+	if _, err := Eval(loop, env, NewBuffer("")); err == nil || !strings.Contains(err.Error(), "recursive load") {
+		t.Errorf("Expected a recursive load error, got %v", err)
+	}
+}
+
+func TestBindArguments(t *testing.T) {
+	arg := func(name string) Value {
+		return NewList(NewSymbol("arg"), NewString(name))
+	}
+	program := []Value{
+		NewList(NewSymbol("search-forward"), arg("OLD")),
+		NewList(NewSymbol("replace-match"), NewList(NewSymbol("concat"), arg("NEW"), NewString("!"))),
+	}
+
+	bound, err := BindArguments(program, map[string]string{"OLD": `say("hi")`, "NEW": "shout"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	buffer := NewBuffer(`say("hi")`)
+	if _, err := Eval(bound, NewDefaultEnvironment(), buffer); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if buffer.String() != "shout!" {
+		t.Errorf("Expected arguments to be substituted, got %q", buffer.String())
+	}
+	if !Equal(program[0], NewList(NewSymbol("search-forward"), arg("OLD"))) {
+		t.Errorf("Expected the program to be left unchanged, got %v", program[0])
+	}
+
+	_, err = BindArguments(program, nil)
+	var missing *MissingArgumentsError
+	if !errors.As(err, &missing) || strings.Join(missing.Names, ",") != "NEW,OLD" {
+		t.Errorf("Expected NEW and OLD to be reported missing, got %v", err)
+	}
+}
+
+func TestEvalEach(t *testing.T) {
+	env := NewDefaultEnvironment()
+	buffer := NewBuffer("foo1 foo22 foo3 bar")
+
+	// Append to each match, failing at foo3 after it has been changed.
+	program := []Value{
+		NewList(NewSymbol("insert"), NewString("!")),
+		NewList(NewSymbol("assert"),
+			NewList(NewSymbol("string-match"), NewString("^foo[12]"), NewList(NewSymbol("buffer-substring"), NewList(NewSymbol("mark")), NewList(NewSymbol("point")))),
+			NewString("no threes")),
+	}
+	result := EvalEach(program, env, buffer, regexp.MustCompile(`foo[0-9]+`))
+	if result.Processed != 2 || result.Skipped != 0 || result.Failed != 1 {
+		t.Errorf("Unexpected counts: %s", result)
+	}
+	if buffer.String() != "foo1! foo22! foo3 bar" {
+		t.Errorf("Unexpected buffer %q", buffer.String())
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error(), "match 3 at line 1: assertion failed: no threes") {
+		t.Errorf("Unexpected errors: %v", result.Errors)
+	}
+
+	buffer = NewBuffer("aa aa")
+	result = EvalEach([]Value{NewList(NewSymbol("delete-char"), NewNumber(1))}, env, buffer, regexp.MustCompile(`a`))
+	if result.Processed != 2 || result.Skipped != 2 || buffer.String() != "a a" {
+		t.Errorf("Expected matches deleted by earlier runs to be skipped, got %s and %q", result, buffer.String())
+	}
+	if len(buffer.markers) != 0 {
+		t.Errorf("Expected the match markers to be removed, got %d markers", len(buffer.markers))
+	}
+}
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		text   string
+		coding Coding
+	}{
+		{name: "unix", input: "a\nb\n", text: "a\nb\n", coding: Coding{Encoding: EncodingUTF8, EOL: EOLUnix}},
+		{name: "dos", input: "a\r\nb\r\n", text: "a\nb\n", coding: Coding{Encoding: EncodingUTF8, EOL: EOLDOS}},
+		{name: "mac", input: "a\rb\r", text: "a\nb\n", coding: Coding{Encoding: EncodingUTF8, EOL: EOLMac}},
+		{name: "mixed is kept", input: "a\r\nb\n", text: "a\r\nb\n", coding: Coding{Encoding: EncodingUTF8, EOL: EOLUnix}},
+		{name: "bom", input: "\xef\xbb\xbfa\r\n", text: "a\n", coding: Coding{Encoding: EncodingUTF8, EOL: EOLDOS, BOM: true}},
+		{name: "no line endings", input: "a", text: "a", coding: Coding{Encoding: EncodingUTF8, EOL: EOLUnix}},
+		{name: "utf-16le", input: "\xff\xfea\x00\r\x00\n\x00", text: "a\n", coding: Coding{Encoding: EncodingUTF16LE, EOL: EOLDOS, BOM: true}},
+		{name: "utf-16be without bom", input: "\x00a\x00\n\x00\xe9\x00\n", text: "a\né\n", coding: Coding{Encoding: EncodingUTF16BE, EOL: EOLUnix}},
+		{name: "iso-8859-1", input: "caf\xe9\n", text: "café\n", coding: Coding{Encoding: EncodingLatin1, EOL: EOLUnix}},
+		{name: "windows-1252", input: "\x93caf\xe9\x94\n", text: "“café”\n", coding: Coding{Encoding: EncodingWindows1252, EOL: EOLUnix}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, coding := DecodeText(tt.input)
+			if text != tt.text || coding != tt.coding {
+				t.Errorf("DecodeText(%q) = %q, %+v, want %q, %+v", tt.input, text, coding, tt.text, tt.coding)
+			}
+			if encoded, err := coding.Encode(text); err != nil || encoded != tt.input {
+				t.Errorf("Encode() = %q, %v, want the input %q", encoded, err, tt.input)
+			}
+		})
+	}
+
+	latin1 := Coding{Encoding: EncodingLatin1, EOL: EOLDOS}
+	if _, err := latin1.Encode("café\n10 €\n"); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Encode() of € in iso-8859-1 error = %v, want an error on line 2", err)
+	}
+	if text, _ := DecodeTextAs("caf\xc3\xa9", EncodingLatin1); text != "cafÃ©" {
+		t.Errorf("DecodeTextAs() = %q, want the bytes read as iso-8859-1", text)
+	}
+}
@@ -0,0 +1,74 @@
+package edlisp
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// ErrFileAccessDisabled is returned by file builtins when the environment has no FileSystem.
+var ErrFileAccessDisabled = errors.New("file access is disabled")
+
+// FileSystem is the file access available to scripts.
+// Like io/fs, names are slash-separated paths relative to the root of the file system.
+// Reading goes through fs.FS; writing through the additional methods.
+type FileSystem interface {
+	fs.FS
+
+	// WriteFile replaces the contents of the named file, creating it if necessary.
+	WriteFile(name string, data []byte) error
+
+	// AppendFile appends data to the named file, creating it if necessary.
+	AppendFile(name string, data []byte) error
+}
+
+// dirFS is a FileSystem confined to a directory on disk.
+type dirFS struct {
+	root *os.Root
+}
+
+// DirFS returns a FileSystem for the files below dir.
+// Names that would leave dir, including through symbolic links, are rejected.
+func DirFS(dir string) (FileSystem, error) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &dirFS{root: root}, nil
+}
+
+// Open opens the named file for reading.
+func (d *dirFS) Open(name string) (fs.File, error) {
+	return d.root.FS().Open(name)
+}
+
+// WriteFile replaces the contents of the named file, creating it if necessary.
+func (d *dirFS) WriteFile(name string, data []byte) error {
+	return d.write(name, data, os.O_TRUNC)
+}
+
+// AppendFile appends data to the named file, creating it if necessary.
+func (d *dirFS) AppendFile(name string, data []byte) error {
+	return d.write(name, data, os.O_APPEND)
+}
+
+func (d *dirFS) write(name string, data []byte, flag int) error {
+	f, err := d.root.OpenFile(name, os.O_WRONLY|os.O_CREATE|flag, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// fileSystem returns the FileSystem scripts evaluated in buffer may use.
+func (b *Buffer) fileSystem() (FileSystem, error) {
+	env := b.State().env
+	if env == nil || env.FileSystem == nil {
+		return nil, ErrFileAccessDisabled
+	}
+	return env.FileSystem, nil
+}
@@ -1,6 +1,20 @@
 package edlisp
 
+import "fmt"
+
 // isLetter checks if a character is a letter or digit (word character)
 func isLetter(ch byte) bool {
 	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
 }
+
+// optionalMessage returns the string argument at index as a user supplied
+// message, or defaultMessage if the argument has been omitted.
+func optionalMessage(fnName string, args []Value, index int, defaultMessage string) (string, error) {
+	if len(args) <= index {
+		return defaultMessage, nil
+	}
+	if !IsA(args[index], TheStringKind) {
+		return "", fmt.Errorf("%s expects a string message", fnName)
+	}
+	return args[index].(*String).Value, nil
+}
@@ -0,0 +1,123 @@
+package edlisp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrLoadDisabled is returned by load when the environment has no LoadPath or ScriptParser.
+var ErrLoadDisabled = errors.New("loading scripts is disabled")
+
+// Script is a parsed script file.
+type Script struct {
+	// Name is the name the script was loaded as.
+	Name string
+
+	// Program holds the top-level expressions of the script.
+	Program []Value
+
+	// Lines holds the line each expression of Program starts on, or 0 if it is unknown.
+	Lines []int
+}
+
+// ScriptParser parses the source of the script file called name.
+// The script format is chosen by the extension of name.
+type ScriptParser func(name, source string) (*Script, error)
+
+// LoadError locates an error in a script file.
+type LoadError struct {
+	// File is the name of the script file.
+	File string
+
+	// Line is the line of the expression that failed, or 0 if it is unknown.
+	Line int
+
+	// Err is the error that occurred.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *LoadError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.File, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// readScript finds name in the load path of env and returns its path and contents.
+// Names are relative to the load path directories and may not leave them.
+func readScript(env *Environment, name string) (string, string, error) {
+	for _, dir := range env.LoadPath {
+		root, err := os.OpenRoot(dir)
+		if err != nil {
+			continue
+		}
+		f, err := root.Open(filepath.FromSlash(name))
+		if err != nil {
+			root.Close()
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return "", "", err
+		}
+		source, err := io.ReadAll(f)
+		f.Close()
+		root.Close()
+		if err != nil {
+			return "", "", err
+		}
+		return filepath.Join(dir, filepath.FromSlash(name)), string(source), nil
+	}
+	return "", "", fmt.Errorf("%s not found in load path %s", name, strings.Join(env.LoadPath, string(filepath.ListSeparator)))
+}
+
+// loadScript reads, parses and evaluates the script file called name in buffer.
+func (b *Buffer) loadScript(name string) error {
+	state := b.State()
+	env := state.env
+	if env == nil || len(env.LoadPath) == 0 || env.ParseScript == nil {
+		return ErrLoadDisabled
+	}
+
+	path, source, err := readScript(env, name)
+	if err != nil {
+		return err
+	}
+	for _, loading := range state.loading {
+		if loading == path {
+			return fmt.Errorf("recursive load of %s (%s -> %s)", name, strings.Join(state.loading, " -> "), path)
+		}
+	}
+	state.loading = append(state.loading, path)
+	defer func() { state.loading = state.loading[:len(state.loading)-1] }()
+
+	script, err := env.ParseScript(name, source)
+	if err != nil {
+		return err
+	}
+	program, err := BindArguments(script.Program, env.Args)
+	if err != nil {
+		return &LoadError{File: name, Err: err}
+	}
+
+	for i, expr := range program {
+		if _, err := evalExpression(expr, env, b); err != nil {
+			line := 0
+			if i < len(script.Lines) {
+				line = script.Lines[i]
+			}
+			return &LoadError{File: name, Line: line, Err: err}
+		}
+	}
+	return nil
+}
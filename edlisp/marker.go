@@ -0,0 +1,46 @@
+package edlisp
+
+// marker is a buffer position that moves along with the text around it
+// when the buffer is edited.
+type marker struct {
+	pos int
+
+	// advance makes the marker move past text inserted exactly at its position
+	advance bool
+}
+
+// addMarker creates a marker at the 1-based position pos and registers it
+// with the buffer so that edits keep it up to date.
+func (b *Buffer) addMarker(pos int) *marker {
+	m := &marker{pos: pos}
+	b.markers = append(b.markers, m)
+	return m
+}
+
+// removeMarker stops tracking edits for m.
+func (b *Buffer) removeMarker(m *marker) {
+	for i, other := range b.markers {
+		if other == m {
+			b.markers = append(b.markers[:i], b.markers[i+1:]...)
+			return
+		}
+	}
+}
+
+// adjustMarkers updates all markers after the 0-based range [start, end)
+// has been replaced by inserted bytes of text.
+// Markers after the range shift by the change in length, markers inside
+// the deleted range collapse to its start, and markers before it stay put.
+// Text inserted exactly at a marker only moves it if the marker advances.
+func (b *Buffer) adjustMarkers(start, end, inserted int) {
+	for _, m := range b.markers {
+		offset := m.pos - 1
+		switch {
+		case offset >= end && (offset > start || m.advance):
+			offset += inserted - (end - start)
+		case offset > start:
+			offset = start
+		}
+		m.pos = offset + 1
+	}
+}
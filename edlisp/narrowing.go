@@ -0,0 +1,74 @@
+package edlisp
+
+// restriction records the accessible portion of a narrowed buffer.
+// Both ends are markers so that edits inside the buffer keep them in place.
+type restriction struct {
+	start *marker
+	end   *marker
+}
+
+// PointMin returns the smallest accessible position.
+// This is 1 unless the buffer is narrowed.
+func (b *Buffer) PointMin() int {
+	if b.restriction == nil {
+		return 1
+	}
+	return b.restriction.start.pos
+}
+
+// PointMax returns the largest accessible position.
+// This is one past the last character unless the buffer is narrowed.
+func (b *Buffer) PointMax() int {
+	if b.restriction == nil {
+		return b.content.Len() + 1
+	}
+	return b.restriction.end.pos
+}
+
+// IsNarrowed reports whether only a part of the buffer is accessible.
+func (b *Buffer) IsNarrowed() bool {
+	return b.restriction != nil
+}
+
+// Narrow restricts editing and searching to the text between start and end.
+// The positions may be given in any order and are clamped to the buffer.
+// Point and mark are moved into the accessible portion if necessary.
+// String still returns the whole buffer.
+func (b *Buffer) Narrow(start, end int) {
+	if start > end {
+		start, end = end, start
+	}
+	start = clampPosition(start, 1, b.content.Len()+1)
+	end = clampPosition(end, 1, b.content.Len()+1)
+
+	b.Widen()
+	b.restriction = &restriction{
+		start: b.addMarker(start),
+		end:   &marker{pos: end, advance: true},
+	}
+	b.markers = append(b.markers, b.restriction.end)
+
+	b.SetPoint(b.point)
+	b.mark = clampPosition(b.mark, start, end)
+}
+
+// Widen makes the whole buffer accessible again.
+func (b *Buffer) Widen() {
+	if b.restriction == nil {
+		return
+	}
+	b.removeMarker(b.restriction.start)
+	b.removeMarker(b.restriction.end)
+	b.restriction = nil
+}
+
+// clampPosition limits pos to the range [low, high].
+func clampPosition(pos, low, high int) int {
+	if pos < low {
+		return low
+	}
+	if pos > high {
+		return high
+	}
+	return pos
+}
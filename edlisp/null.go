@@ -0,0 +1,28 @@
+package edlisp
+
+// NullKind represents the kind for the nil value.
+type NullKind struct{}
+
+// KindName returns the unique name for null kind.
+func (kind *NullKind) KindName() string {
+	return "null"
+}
+
+// TheNullKind is the singleton instance of NullKind.
+var TheNullKind = &NullKind{}
+
+// Null represents the absence of a value, written as nil in scripts.
+type Null struct{}
+
+// Nil is the canonical nil value.
+var Nil = &Null{}
+
+// Kind returns the ValueKind for nil.
+func (n *Null) Kind() ValueKind {
+	return TheNullKind
+}
+
+// String returns the string representation of nil.
+func (n *Null) String() string {
+	return "nil"
+}
@@ -0,0 +1,65 @@
+package edlisp
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// OutputSink receives the text scripts print with message and princ.
+// Output is kept separate from the buffer being edited.
+type OutputSink interface {
+	Output(text string) error
+}
+
+// writerSink writes script output to an io.Writer.
+type writerSink struct {
+	w io.Writer
+}
+
+// WriterSink returns an OutputSink writing to w, such as os.Stderr.
+func WriterSink(w io.Writer) OutputSink {
+	return &writerSink{w: w}
+}
+
+// Output writes text to the underlying writer.
+func (s *writerSink) Output(text string) error {
+	_, err := io.WriteString(s.w, text)
+	return err
+}
+
+// CapturedOutput is an OutputSink collecting script output in memory.
+// Each call to message or princ adds one entry.
+type CapturedOutput struct {
+	Entries []string
+}
+
+// Output records text as a new entry.
+func (c *CapturedOutput) Output(text string) error {
+	c.Entries = append(c.Entries, text)
+	return nil
+}
+
+// String returns all entries joined together.
+func (c *CapturedOutput) String() string {
+	return strings.Join(c.Entries, "")
+}
+
+// output sends text to the output sink of the environment.
+// Without a sink the text is discarded.
+func (b *Buffer) output(text string) error {
+	env := b.State().env
+	if env == nil || env.Output == nil {
+		return nil
+	}
+	return env.Output.Output(text)
+}
+
+// princString returns the printed representation of value without quoting,
+// as used by princ and the %s directive of message.
+func princString(value Value) string {
+	if str, ok := value.(*String); ok {
+		return str.Value
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -13,9 +13,10 @@ import (
 // ParseJSONReader parses texted scripts from a JSON-encoded io.Reader.
 // Each JSON array represents a command list where:
 // - The first element must be a string (the command/symbol)
-// - Subsequent elements can be strings, numbers, or nested arrays
+// - Subsequent elements can be strings, numbers, booleans, null, or nested arrays
 // - Arrays are converted to edlisp Lists
 // - Numbers are converted to edlisp Numbers
+// - true becomes t, false a false Boolean, and null becomes nil
 // - Strings are converted to edlisp Strings (except the first element which becomes a Symbol)
 func ParseJSONReader(r io.Reader) ([]edlisp.Value, error) {
 	decoder := json.NewDecoder(r)
@@ -128,9 +129,11 @@ func convertJSONItem(item interface{}) (edlisp.Value, error) {
 		// Nested arrays become Lists
 		return convertJSONArray(v)
 	case bool:
-		return nil, fmt.Errorf("boolean values are not supported in texted JSON")
+		// false becomes nil, the only false value scripts can write
+		return edlisp.Bool(v), nil
 	case nil:
-		return nil, fmt.Errorf("null values are not supported in texted JSON")
+		// null is what the JSON writer emits for nil
+		return edlisp.Nil, nil
 	default:
 		return nil, fmt.Errorf("unsupported JSON type: %T (value: %v)", item, item)
 	}
@@ -175,7 +178,10 @@ func validateJSONValue(value interface{}, isTopLevel bool) error {
 		return nil
 
 	case bool, nil:
-		return fmt.Errorf("boolean and null values are not supported in texted JSON")
+		if isTopLevel {
+			return fmt.Errorf("top-level booleans and null are not allowed in texted JSON")
+		}
+		return nil
 
 	default:
 		rv := reflect.ValueOf(value)
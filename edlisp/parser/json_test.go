@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/dhamidi/texted/edlisp"
+	"github.com/dhamidi/texted/edlisp/writer"
 )
 
 func TestParseJSONString_SingleCommand(t *testing.T) {
@@ -197,8 +198,8 @@ func TestParseJSONString_ErrorCases(t *testing.T) {
 		{"non-string first element", `[[42, "test"]]`},
 		{"top-level string", `["not-array"]`},
 		{"top-level number", `[42]`},
-		{"boolean value", `[["command", true]]`},
-		{"null value", `[["command", null]]`},
+		{"top-level boolean", `[true]`},
+		{"top-level null", `[null]`},
 		{"object value", `[["command", {"key": "value"}]]`},
 		{"invalid JSON", `[["command", "test"`},
 	}
@@ -312,7 +313,7 @@ func TestConvertJSONArray(t *testing.T) {
 		{
 			name:     "boolean argument",
 			input:    []interface{}{"command", true},
-			hasError: true,
+			hasError: false,
 		},
 	}
 
@@ -363,14 +364,14 @@ func TestConvertJSONItem(t *testing.T) {
 		{
 			name:         "boolean",
 			input:        true,
-			expectedType: "",
-			hasError:     true,
+			expectedType: "*edlisp.Boolean",
+			hasError:     false,
 		},
 		{
 			name:         "null",
 			input:        nil,
-			expectedType: "",
-			hasError:     true,
+			expectedType: "*edlisp.Null",
+			hasError:     false,
 		},
 	}
 
@@ -399,6 +400,10 @@ func TestConvertJSONItem(t *testing.T) {
 					actualType = "*edlisp.List"
 				case *edlisp.Symbol:
 					actualType = "*edlisp.Symbol"
+				case *edlisp.Boolean:
+					actualType = "*edlisp.Boolean"
+				case *edlisp.Null:
+					actualType = "*edlisp.Null"
 				}
 
 				if actualType != tc.expectedType {
@@ -448,11 +453,16 @@ func TestValidateJSONFormat(t *testing.T) {
 		{
 			name:     "boolean in array",
 			input:    []interface{}{"command", true},
-			hasError: true,
+			hasError: false,
 		},
 		{
 			name:     "null in array",
 			input:    []interface{}{"command", nil},
+			hasError: false,
+		},
+		{
+			name:     "top-level null",
+			input:    nil,
 			hasError: true,
 		},
 		{
@@ -496,3 +506,42 @@ func TestParseJSONReader_InvalidJSON(t *testing.T) {
 		t.Errorf("expected error for invalid JSON")
 	}
 }
+
+func TestParseJSONReader_RoundTrip(t *testing.T) {
+	expressions := []edlisp.Value{
+		edlisp.NewList(edlisp.NewSymbol("assert"), edlisp.Nil, edlisp.NewString("x")),
+		edlisp.NewList(edlisp.NewSymbol("list"), edlisp.T, edlisp.Nil, edlisp.NewNumber(1)),
+	}
+
+	var buf strings.Builder
+	if err := (&writer.JSONWriter{}).Write(&buf, expressions); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), `["assert",null,"x"]`) {
+		t.Fatalf("unexpected JSON %q", buf.String())
+	}
+
+	result, err := ParseJSONReader(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatalf("parsing %q: %v", buf.String(), err)
+	}
+	if len(result) != len(expressions) {
+		t.Fatalf("expected %d expressions, got %d", len(expressions), len(result))
+	}
+	for i := range expressions {
+		if !edlisp.Equal(result[i], expressions[i]) {
+			t.Errorf("expression %d: expected %v, got %v", i, expressions[i], result[i])
+		}
+	}
+}
+
+func TestParseJSONReader_FalseIsNil(t *testing.T) {
+	result, err := ParseJSONReader(strings.NewReader(`["list", true, false, null]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := edlisp.NewList(edlisp.NewSymbol("list"), edlisp.T, edlisp.Nil, edlisp.Nil)
+	if len(result) != 1 || !edlisp.Equal(result[0], want) {
+		t.Errorf("expected %v, got %v", want, result)
+	}
+}
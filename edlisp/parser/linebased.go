@@ -18,14 +18,23 @@ import (
 // 2. If the next character is '(', read a regular S-expression list
 // 3. Otherwise build a list by reading tokens until a newline is encountered
 // 4. Semicolons are treated as line separators (equivalent to newlines)
+// 5. Comments are removed first, see stripComment
 func ParseReader(r io.Reader) ([]edlisp.Value, error) {
+	expressions, _, err := parseLines(r)
+	return expressions, err
+}
+
+// parseLines parses r like ParseReader and also returns the line each expression is on.
+// Parse errors are reported as a *LineError.
+func parseLines(r io.Reader) ([]edlisp.Value, []int, error) {
 	scanner := bufio.NewScanner(r)
 	var expressions []edlisp.Value
+	var lines []int
 
-	for scanner.Scan() {
-		line := strings.TrimLeftFunc(scanner.Text(), unicode.IsSpace)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := stripComment(strings.TrimLeftFunc(scanner.Text(), unicode.IsSpace))
 		if line == "" {
-			continue // Skip empty lines
+			continue // Skip empty lines and comment lines
 		}
 
 		// Split line on semicolons (outside of quotes)
@@ -38,17 +47,18 @@ func ParseReader(r io.Reader) ([]edlisp.Value, error) {
 
 			expr, err := parseLine(command)
 			if err != nil {
-				return nil, err
+				return nil, nil, &LineError{Line: lineNumber, Err: err}
 			}
 			expressions = append(expressions, expr)
+			lines = append(lines, lineNumber)
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("reading input: %w", err)
+		return nil, nil, fmt.Errorf("reading input: %w", err)
 	}
 
-	return expressions, nil
+	return expressions, lines, nil
 }
 
 // ParseString parses a single texted script from a string.
@@ -252,6 +262,49 @@ func parseList(tokens []string, start int) (edlisp.Value, int, error) {
 	return &edlisp.List{Elements: elements}, pos + 1, nil
 }
 
+// stripComment removes the comment from a line without leading whitespace.
+// A line starting with ';' is a comment, as in Emacs Lisp, and so is the text
+// after a ';' following the S-expression of a line that starts with one.
+// Like in the shell, '#' at the start of a word begins a comment in any line,
+// which also skips the #! line of executable scripts.
+func stripComment(line string) string {
+	if strings.HasPrefix(line, ";") {
+		return ""
+	}
+
+	sexp := strings.HasPrefix(line, "(")
+	depth := 0
+	inQuotes := false
+	escapeNext := false
+	wordStart := true
+
+	for i, r := range line {
+		if escapeNext {
+			escapeNext = false
+			continue
+		}
+
+		switch {
+		case inQuotes && r == '\\':
+			escapeNext = true
+		case r == '"':
+			inQuotes = !inQuotes
+		case inQuotes:
+		case r == '#' && wordStart:
+			return line[:i]
+		case r == ';' && sexp && depth == 0:
+			return line[:i]
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		}
+		wordStart = !inQuotes && (unicode.IsSpace(r) || r == ';')
+	}
+
+	return line
+}
+
 // splitOnSemicolons splits a line on semicolons that are not inside quoted strings.
 func splitOnSemicolons(line string) []string {
 	var parts []string
@@ -315,6 +368,33 @@ func parseToken(token string) (edlisp.Value, error) {
 		return &edlisp.Number{Value: num}, nil
 	}
 
+	// t and nil are the canonical truth values
+	switch token {
+	case "t":
+		return edlisp.T, nil
+	case "nil":
+		return edlisp.Nil, nil
+	}
+
+	// $NAME refers to a script argument
+	if name, ok := strings.CutPrefix(token, "$"); ok && isArgumentName(name) {
+		return edlisp.NewList(edlisp.NewSymbol("arg"), edlisp.NewString(name)), nil
+	}
+
 	// Otherwise, it's a symbol
 	return &edlisp.Symbol{Name: token}, nil
 }
+
+// isArgumentName reports whether name can be used in a $NAME placeholder:
+// a letter or underscore followed by letters, digits, underscores or dashes.
+func isArgumentName(name string) bool {
+	for i, r := range name {
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+		case i > 0 && (r == '-' || unicode.IsDigit(r)):
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
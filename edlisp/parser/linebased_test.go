@@ -364,6 +364,53 @@ func TestParseToken(t *testing.T) {
 	}
 }
 
+func TestParseToken_BooleanValues(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected edlisp.Value
+	}{
+		{`t`, edlisp.T},
+		{`nil`, edlisp.Nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			result, err := parseToken(tc.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tc.expected {
+				t.Errorf("expected %v, got %#v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseToken_ArgumentPlaceholders(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected edlisp.Value
+	}{
+		{`$OLD`, edlisp.NewList(edlisp.NewSymbol("arg"), edlisp.NewString("OLD"))},
+		{`$new_name-2`, edlisp.NewList(edlisp.NewSymbol("arg"), edlisp.NewString("new_name-2"))},
+		{`$`, edlisp.NewSymbol("$")},
+		{`$1`, edlisp.NewSymbol("$1")},
+		{`"$OLD"`, edlisp.NewString("$OLD")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			result, err := parseToken(tc.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !edlisp.Equal(result, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
 func TestParseString_NestedSExpression_BufferSubstring(t *testing.T) {
 	input := `buffer-substring (region-beginning) (region-end)`
 	result, err := ParseString(input)
@@ -682,3 +729,52 @@ func TestSplitOnSemicolons(t *testing.T) {
 		})
 	}
 }
+
+func TestStripComment(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{`# a comment`, ``},
+		{`#!/usr/bin/env -S texted run`, ``},
+		{`;; a comment`, ``},
+		{`insert "x" # a comment`, `insert "x" `},
+		{`insert "# not a comment"; goto-char 1`, `insert "# not a comment"; goto-char 1`},
+		{`insert "a\"#b"`, `insert "a\"#b"`},
+		{`insert x#y`, `insert x#y`},
+		{`goto-char 1;# a comment`, `goto-char 1;`},
+		{`(insert "x") ; a comment`, `(insert "x") `},
+		{`(insert "x;y")`, `(insert "x;y")`},
+		{`(progn (insert "x") ; not closed yet`, `(progn (insert "x") ; not closed yet`},
+		{`set-mark; goto-char 10`, `set-mark; goto-char 10`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			if result := stripComment(tc.input); result != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseString_Comments(t *testing.T) {
+	input := "#!/usr/bin/env -S texted run\n# Set a mark.\nset-mark # here\n;; Lisp comment\n(goto-char 10) ; trailing\n"
+	result, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []edlisp.Value{
+		edlisp.NewList(edlisp.NewSymbol("set-mark")),
+		edlisp.NewList(edlisp.NewSymbol("goto-char"), edlisp.NewNumber(10)),
+	}
+	if len(result) != len(expected) {
+		t.Fatalf("expected %d expressions, got %d: %v", len(expected), len(result), result)
+	}
+	for i := range expected {
+		if !edlisp.Equal(result[i], expected[i]) {
+			t.Errorf("expression %d: expected %v, got %v", i, expected[i], result[i])
+		}
+	}
+}
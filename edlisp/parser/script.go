@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/dhamidi/texted/edlisp"
+)
+
+// LineError is a parse error on a specific line of a script.
+type LineError struct {
+	// Line is the 1-based line the error occurred on.
+	Line int
+
+	// Err is the parse error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *LineError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the parse error.
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// FormatForFile returns the script format for a file name based on its extension:
+// json for .json, sexp for .el and shell for anything else, including .elsh.
+func FormatForFile(name string) string {
+	switch path.Ext(name) {
+	case ".json":
+		return "json"
+	case ".el":
+		return "sexp"
+	default:
+		return "shell"
+	}
+}
+
+// ParseScript parses the source of the script file called name in the format given by FormatForFile.
+// It records the line each top-level expression starts on, and parse errors
+// are reported as an *edlisp.LoadError locating them in the file.
+// ParseScript is an edlisp.ScriptParser.
+func ParseScript(name, source string) (*edlisp.Script, error) {
+	var program []edlisp.Value
+	var lines []int
+	var err error
+	if FormatForFile(name) == "json" {
+		program, lines, err = parseJSONLines(source)
+	} else {
+		program, lines, err = parseLines(strings.NewReader(source))
+	}
+
+	if err != nil {
+		loadErr := &edlisp.LoadError{File: name, Err: err}
+		if lineErr, ok := err.(*LineError); ok {
+			loadErr.Line = lineErr.Line
+		}
+		return nil, loadErr
+	}
+
+	return &edlisp.Script{Name: name, Program: program, Lines: lines}, nil
+}
+
+// parseJSONLines parses a JSON script like ParseJSONString and also returns
+// the line each top-level command starts on.
+func parseJSONLines(source string) ([]edlisp.Value, []int, error) {
+	decoder := json.NewDecoder(strings.NewReader(source))
+	lineAt := func(offset int64) int {
+		rest := strings.TrimLeft(source[offset:], " \t\r\n,")
+		return strings.Count(source[:len(source)-len(rest)], "\n") + 1
+	}
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, nil, fmt.Errorf("JSON decode error: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, nil, &LineError{Line: 1, Err: fmt.Errorf("a JSON script must be an array of commands")}
+	}
+
+	var expressions []edlisp.Value
+	var lines []int
+	for i := 0; decoder.More(); i++ {
+		line := lineAt(decoder.InputOffset())
+
+		var rawValue interface{}
+		if err := decoder.Decode(&rawValue); err != nil {
+			return nil, nil, &LineError{Line: line, Err: fmt.Errorf("JSON decode error: %w", err)}
+		}
+		if err := ValidateJSONFormat(rawValue); err != nil {
+			return nil, nil, &LineError{Line: line, Err: fmt.Errorf("invalid format at index %d: %w", i, err)}
+		}
+		expr, err := convertJSONValue(rawValue)
+		if err != nil {
+			return nil, nil, &LineError{Line: line, Err: err}
+		}
+
+		expressions = append(expressions, expr)
+		lines = append(lines, line)
+	}
+
+	if _, err := decoder.Token(); err != nil && err != io.EOF {
+		return nil, nil, fmt.Errorf("JSON decode error: %w", err)
+	}
+
+	return expressions, lines, nil
+}
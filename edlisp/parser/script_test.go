@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dhamidi/texted/edlisp"
+)
+
+func TestFormatForFile(t *testing.T) {
+	testCases := map[string]string{
+		"lib/imports.elsh": "shell",
+		"lib/imports.el":   "sexp",
+		"lib/imports.json": "json",
+		"lib/imports":      "shell",
+	}
+
+	for name, expected := range testCases {
+		if format := FormatForFile(name); format != expected {
+			t.Errorf("FormatForFile(%q) = %q, expected %q", name, format, expected)
+		}
+	}
+}
+
+func TestParseScript_Lines(t *testing.T) {
+	testCases := []struct {
+		name   string
+		source string
+		lines  []int
+	}{
+		{"lib/a.elsh", "search-forward \"import\"\n\nforward-char 1; insert \"x\"\n", []int{1, 3, 3}},
+		{"lib/a.el", "(search-forward \"import\")\n(insert \"x\")\n", []int{1, 2}},
+		{"lib/b.elsh", "# Add an x.\nsearch-forward \"import\" # first\ninsert \"x\"\n", []int{2, 3}},
+		{"lib/b.el", ";; Add an x.\n(search-forward \"import\") ; first\n(insert \"x\")\n", []int{2, 3}},
+		{"lib/a.json", "[\n  [\"search-forward\", \"import\"],\n\n  [\"insert\", \"x\"]\n]\n", []int{2, 4}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			script, err := ParseScript(tc.name, tc.source)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(script.Program) != len(tc.lines) {
+				t.Fatalf("expected %d expressions, got %d", len(tc.lines), len(script.Program))
+			}
+			for i, line := range tc.lines {
+				if script.Lines[i] != line {
+					t.Errorf("expression %d: expected line %d, got %d", i, line, script.Lines[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseScript_ErrorLocation(t *testing.T) {
+	testCases := []struct {
+		name   string
+		source string
+		line   int
+	}{
+		{"lib/a.elsh", "insert \"ok\"\ninsert \"unterminated\n", 2},
+		{"lib/a.json", "[\n  [\"insert\", \"ok\"],\n  [true]\n]\n", 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseScript(tc.name, tc.source)
+			var loadErr *edlisp.LoadError
+			if !errors.As(err, &loadErr) {
+				t.Fatalf("expected a LoadError, got %v", err)
+			}
+			if loadErr.File != tc.name || loadErr.Line != tc.line {
+				t.Errorf("expected error at %s:%d, got %s:%d", tc.name, tc.line, loadErr.File, loadErr.Line)
+			}
+		})
+	}
+}
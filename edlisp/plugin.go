@@ -0,0 +1,384 @@
+package edlisp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// PluginPrefix is the file name prefix of programs that implement builtins.
+// An executable called texted-fn-NAME on PATH provides the builtin NAME.
+const PluginPrefix = "texted-fn-"
+
+// describeTimeout limits how long a plugin may take to describe itself.
+const describeTimeout = 5 * time.Second
+
+// Plugin is a builtin implemented by an external program.
+//
+// Plugins describe themselves when run with the single argument --describe.
+// They print their documentation as a JSON object with the fields name,
+// summary, description, category, parameters (objects with name, type,
+// description and optional), examples (objects with description, input,
+// buffer and output) and see_also.
+//
+// When the builtin is called, the program reads a JSON request from stdin:
+//
+//	{"args": [...], "buffer": "...", "point": 1, "mark": 1,
+//	 "point_min": 1, "point_max": 1, "region": "..."}
+//
+// buffer is the accessible portion of the current buffer, which starts at
+// point_min; region is the text between point and mark. Strings, numbers,
+// T and nil arguments become the corresponding JSON values, symbols become
+// strings and lists become arrays.
+//
+// It answers with a JSON object on stdout:
+//
+//	{"value": ..., "buffer": "...", "point": 1, "error": "..."}
+//
+// value is the result of the builtin and defaults to nil. If buffer is
+// present it replaces the accessible portion of the buffer, and point, if
+// present, moves point afterwards. A non-empty error fails the call with
+// that message, as does exiting with a non-zero status.
+type Plugin struct {
+	// Path is the program to run.
+	Path string
+
+	// Doc is the documentation the program reported.
+	Doc FunctionDoc
+}
+
+// pluginDescription is the JSON form of the documentation printed by --describe.
+type pluginDescription struct {
+	Name        string `json:"name"`
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	Parameters  []struct {
+		Name        string `json:"name"`
+		Type        string `json:"type"`
+		Description string `json:"description"`
+		Optional    bool   `json:"optional"`
+	} `json:"parameters"`
+	Examples []struct {
+		Description string `json:"description"`
+		Input       string `json:"input"`
+		Buffer      string `json:"buffer"`
+		Output      string `json:"output"`
+	} `json:"examples"`
+	SeeAlso []string `json:"see_also"`
+}
+
+type pluginRequest struct {
+	Args     []any  `json:"args"`
+	Buffer   string `json:"buffer"`
+	Point    int    `json:"point"`
+	Mark     int    `json:"mark"`
+	PointMin int    `json:"point_min"`
+	PointMax int    `json:"point_max"`
+	Region   string `json:"region"`
+}
+
+type pluginResponse struct {
+	Value  any     `json:"value"`
+	Buffer *string `json:"buffer"`
+	Point  *int    `json:"point"`
+	Error  string  `json:"error"`
+}
+
+// DescribePlugin runs the program at path with --describe and returns the plugin it describes.
+// A program called texted-fn-NAME must describe the builtin NAME; other
+// programs must report a name.
+func DescribePlugin(path string) (*Plugin, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), describeTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := commandContext(ctx, path, "--describe")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("describing plugin %s: %v: %s", path, err, message)
+		}
+		return nil, fmt.Errorf("describing plugin %s: %v", path, err)
+	}
+
+	var description pluginDescription
+	if err := json.Unmarshal(stdout.Bytes(), &description); err != nil {
+		return nil, fmt.Errorf("describing plugin %s: invalid description: %v", path, err)
+	}
+
+	name := strings.TrimPrefix(filepath.Base(path), PluginPrefix)
+	switch {
+	case !strings.HasPrefix(filepath.Base(path), PluginPrefix) && description.Name == "":
+		return nil, fmt.Errorf("describing plugin %s: description has no name", path)
+	case description.Name == "":
+		description.Name = name
+	case strings.HasPrefix(filepath.Base(path), PluginPrefix) && description.Name != name:
+		return nil, fmt.Errorf("describing plugin %s: describes %q instead of %q", path, description.Name, name)
+	}
+
+	doc := FunctionDoc{
+		Name:        description.Name,
+		Summary:     description.Summary,
+		Description: description.Description,
+		Category:    description.Category,
+		SeeAlso:     description.SeeAlso,
+	}
+	if doc.Category == "" {
+		doc.Category = "plugin"
+	}
+	for _, param := range description.Parameters {
+		doc.Parameters = append(doc.Parameters, ParameterDoc(param))
+	}
+	for _, example := range description.Examples {
+		doc.Examples = append(doc.Examples, ExampleDoc(example))
+	}
+
+	return &Plugin{Path: path, Doc: doc}, nil
+}
+
+// FindPlugins returns the executables named texted-fn-NAME in the directories of pathList,
+// which is formatted like the PATH environment variable.
+// As with PATH lookups, the first directory providing a name wins.
+func FindPlugins(pathList string) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !strings.HasPrefix(name, PluginPrefix) || name == PluginPrefix || seen[name] {
+				continue
+			}
+			path := filepath.Join(dir, name)
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+				continue
+			}
+			seen[name] = true
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// ReadPluginConfig returns the programs listed in a plugin configuration file.
+// The file lists one program per line; blank lines and lines starting with #
+// are ignored, and relative paths are relative to the directory of the file.
+func ReadPluginConfig(name string) ([]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var paths []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(filepath.Dir(name), line)
+		}
+		paths = append(paths, line)
+	}
+	return paths, scanner.Err()
+}
+
+// DefaultPluginConfig returns the location of the plugin configuration file,
+// texted/plugins in the user's configuration directory.
+func DefaultPluginConfig() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "texted", "plugins")
+}
+
+// DiscoverPlugins describes the plugins listed in DefaultPluginConfig and those found on PATH.
+// Plugins that cannot be described or whose name is already taken by a
+// builtin or an earlier plugin are skipped; the returned errors explain why.
+func DiscoverPlugins() ([]*Plugin, []error) {
+	var paths []string
+	var errs []error
+	if config := DefaultPluginConfig(); config != "" {
+		listed, err := ReadPluginConfig(config)
+		if err != nil && !os.IsNotExist(err) {
+			errs = append(errs, fmt.Errorf("reading plugin configuration: %w", err))
+		}
+		paths = append(paths, listed...)
+	}
+	paths = append(paths, FindPlugins(os.Getenv("PATH"))...)
+
+	env := NewDefaultEnvironment()
+	var plugins []*Plugin
+	for _, path := range paths {
+		plugin, err := DescribePlugin(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := env.RegisterPlugin(plugin); err != nil {
+			errs = append(errs, fmt.Errorf("plugin %s: %w", path, err))
+			continue
+		}
+		plugins = append(plugins, plugin)
+	}
+	return plugins, errs
+}
+
+// RegisterPlugin registers the builtin implemented by plugin like Register does.
+func (env *Environment) RegisterPlugin(plugin *Plugin) error {
+	return env.Register(plugin.Doc, plugin.call)
+}
+
+// call runs the plugin program for one call of its builtin in buffer.
+func (p *Plugin) call(args []Value, buffer *Buffer) (Value, error) {
+	name := p.Doc.Name
+	request := pluginRequest{
+		Args:     make([]any, 0, len(args)),
+		Point:    buffer.Point(),
+		Mark:     buffer.Mark(),
+		PointMin: buffer.PointMin(),
+		PointMax: buffer.PointMax(),
+	}
+	for _, arg := range args {
+		request.Args = append(request.Args, valueToJSON(arg))
+	}
+	content := buffer.String()
+	request.Buffer = content[buffer.PointMin()-1 : buffer.PointMax()-1]
+	start, end := min(buffer.Point(), buffer.Mark()), max(buffer.Point(), buffer.Mark())
+	start = clampPosition(start, 1, len(content)+1)
+	end = clampPosition(end, 1, len(content)+1)
+	request.Region = content[start-1 : end-1]
+
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	timeout := DefaultCommandTimeout
+	if env := buffer.State().env; env != nil && env.CommandTimeout > 0 {
+		timeout = env.CommandTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := commandContext(ctx, p.Path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("%s: plugin timed out after %s", name, timeout)
+	}
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%s: plugin failed: %v: %s", name, err, message)
+		}
+		return nil, fmt.Errorf("%s: plugin failed: %v", name, err)
+	}
+
+	var response pluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return nil, fmt.Errorf("%s: invalid plugin response: %v", name, err)
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("%s: %s", name, response.Error)
+	}
+
+	value, err := valueFromJSON(response.Value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid plugin response: %v", name, err)
+	}
+	if response.Buffer != nil {
+		buffer.replaceChanged(buffer.PointMin()-1, buffer.PointMax()-1, *response.Buffer)
+	}
+	if response.Point != nil {
+		buffer.SetPoint(*response.Point)
+	}
+
+	return value, nil
+}
+
+// replaceChanged replaces the bytes between the 0-based offsets start and end
+// with text, touching only the part that actually differs so that point,
+// mark and markers outside of it keep their place.
+func (b *Buffer) replaceChanged(start, end int, text string) {
+	old := b.String()[start:end]
+	prefix := 0
+	for prefix < len(old) && prefix < len(text) && old[prefix] == text[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(text)-prefix &&
+		old[len(old)-1-suffix] == text[len(text)-1-suffix] {
+		suffix++
+	}
+	b.replaceContent(start+prefix, end-suffix, text[prefix:len(text)-suffix])
+}
+
+// valueToJSON converts a value into its JSON representation for plugins.
+func valueToJSON(value Value) any {
+	switch v := value.(type) {
+	case *String:
+		return v.Value
+	case *Number:
+		return v.Value
+	case *Symbol:
+		return v.Name
+	case *Boolean:
+		return v.Value
+	case *List:
+		elements := make([]any, 0, len(v.Elements))
+		for _, element := range v.Elements {
+			elements = append(elements, valueToJSON(element))
+		}
+		return elements
+	default:
+		return nil
+	}
+}
+
+// valueFromJSON converts a JSON value returned by a plugin into a value.
+func valueFromJSON(value any) (Value, error) {
+	switch v := value.(type) {
+	case nil:
+		return Nil, nil
+	case string:
+		return NewString(v), nil
+	case float64:
+		return NewNumber(v), nil
+	case bool:
+		return Bool(v), nil
+	case []any:
+		elements := make([]Value, 0, len(v))
+		for _, element := range v {
+			converted, err := valueFromJSON(element)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, converted)
+		}
+		return NewList(elements...), nil
+	default:
+		return nil, fmt.Errorf("unsupported value %v", value)
+	}
+}
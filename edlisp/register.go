@@ -0,0 +1,138 @@
+package edlisp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Register adds a builtin function to the environment together with its documentation.
+//
+// The function is available to scripts under doc.Name and its documentation is
+// returned by Documentation and AllDocumentation, so it shows up in `texted doc`
+// and the texted_doc MCP tool like the default builtins.
+//
+// doc.Parameters also define the calling convention. Before fn is called, the
+// number of arguments is checked against the required and optional parameters,
+// where a parameter whose type ends in "..." accepts any number of arguments.
+// Arguments for parameters of type "string" or "number" must have that type;
+// other types are not checked.
+//
+// Register fails if doc has no name or the environment already has a function of that name.
+func (env *Environment) Register(doc FunctionDoc, fn BuiltinFn) error {
+	if doc.Name == "" {
+		return fmt.Errorf("cannot register a builtin without a name")
+	}
+	if fn == nil {
+		return fmt.Errorf("cannot register builtin %q without a function", doc.Name)
+	}
+	if _, exists := env.Functions[doc.Name]; exists || IsSpecialForm(doc.Name) {
+		return fmt.Errorf("builtin %q is already defined", doc.Name)
+	}
+
+	env.Functions[doc.Name] = func(args []Value, buffer *Buffer) (Value, error) {
+		if err := checkArguments(doc, args); err != nil {
+			return nil, err
+		}
+		return fn(args, buffer)
+	}
+	env.setDocumentation(doc)
+	return nil
+}
+
+// Documentation returns the documentation of a function or special form available in the environment.
+func (env *Environment) Documentation(name string) (FunctionDoc, bool) {
+	doc, exists := env.docs[name]
+	return doc, exists
+}
+
+// AllDocumentation returns the documentation of all functions and special forms
+// available in the environment, sorted alphabetically by name.
+func (env *Environment) AllDocumentation() []FunctionDoc {
+	docs := make([]FunctionDoc, 0, len(env.docs))
+	for _, doc := range env.docs {
+		docs = append(docs, doc)
+	}
+
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].Name < docs[j].Name
+	})
+
+	return docs
+}
+
+// DocumentationByCategory returns the documentation of all functions in a
+// category, sorted alphabetically by name.
+func (env *Environment) DocumentationByCategory(category string) []FunctionDoc {
+	var docs []FunctionDoc
+	for _, doc := range env.AllDocumentation() {
+		if doc.Category == category {
+			docs = append(docs, doc)
+		}
+	}
+	return docs
+}
+
+// Categories returns all categories of documented functions, sorted alphabetically.
+func (env *Environment) Categories() []string {
+	categorySet := make(map[string]bool)
+	for _, doc := range env.docs {
+		if doc.Category != "" {
+			categorySet[doc.Category] = true
+		}
+	}
+
+	categories := make([]string, 0, len(categorySet))
+	for category := range categorySet {
+		categories = append(categories, category)
+	}
+
+	sort.Strings(categories)
+	return categories
+}
+
+// setDocumentation records doc as the documentation of doc.Name in this environment.
+func (env *Environment) setDocumentation(doc FunctionDoc) {
+	if env.docs == nil {
+		env.docs = make(map[string]FunctionDoc)
+	}
+	env.docs[doc.Name] = doc
+}
+
+// checkArguments validates args against the parameters documented in doc.
+func checkArguments(doc FunctionDoc, args []Value) error {
+	required, optional := 0, 0
+	variadic := false
+	for _, param := range doc.Parameters {
+		switch {
+		case strings.HasSuffix(param.Type, "..."):
+			variadic = true
+		case param.Optional:
+			optional++
+		default:
+			required++
+		}
+	}
+
+	switch {
+	case variadic && len(args) < required:
+		return fmt.Errorf("%s expects at least %d arguments, got %d", doc.Name, required, len(args))
+	case !variadic && optional == 0 && len(args) != required:
+		return fmt.Errorf("%s expects %d arguments, got %d", doc.Name, required, len(args))
+	case !variadic && (len(args) < required || len(args) > required+optional):
+		return fmt.Errorf("%s expects %d to %d arguments, got %d", doc.Name, required, required+optional, len(args))
+	}
+
+	for i, arg := range args {
+		param := doc.Parameters[min(i, len(doc.Parameters)-1)]
+		kind := strings.TrimSuffix(param.Type, "...")
+		switch {
+		case kind == "string" && !IsA(arg, TheStringKind):
+			return fmt.Errorf("%s expects a string for %s", doc.Name, param.Name)
+		case kind == "number" && !IsA(arg, TheNumberKind):
+			return fmt.Errorf("%s expects a number for %s", doc.Name, param.Name)
+		}
+	}
+
+	return nil
+}
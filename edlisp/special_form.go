@@ -0,0 +1,35 @@
+package edlisp
+
+// SpecialFormFn implements a special form. Unlike a BuiltinFn it receives its
+// arguments unevaluated and decides itself which of them to evaluate, and when.
+type SpecialFormFn func(args []Value, env *Environment, buffer *Buffer) (Value, error)
+
+// specialForms maps the names of special forms to their implementations.
+// Special forms take precedence over functions of the same name.
+var specialForms = make(map[string]SpecialFormFn)
+
+// registerSpecialForm makes a special form available to the evaluator.
+// This function is called from init() functions in special_form_*.go files.
+func registerSpecialForm(name string, fn SpecialFormFn) {
+	specialForms[name] = fn
+}
+
+// IsSpecialForm reports whether name refers to a special form.
+func IsSpecialForm(name string) bool {
+	_, exists := specialForms[name]
+	return exists
+}
+
+// evalBody evaluates forms in order and returns the value of the last one.
+// An empty body evaluates to nil.
+func evalBody(forms []Value, env *Environment, buffer *Buffer) (Value, error) {
+	var result Value = Nil
+	for _, form := range forms {
+		val, err := evalExpression(form, env, buffer)
+		if err != nil {
+			return nil, err
+		}
+		result = val
+	}
+	return result, nil
+}
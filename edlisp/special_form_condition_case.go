@@ -0,0 +1,123 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// SpecialFormConditionCase evaluates a protected form and handles errors by kind.
+//
+// The syntax follows Emacs Lisp:
+//
+//	(condition-case nil BODYFORM (CONDITION HANDLER-FORMS...)...)
+//
+// The variable slot must be nil (or the empty list) because texted scripts
+// have no variables. If BODYFORM succeeds, its value is returned. If it fails,
+// the first handler whose CONDITION matches the kind of the error (see
+// ErrorKind) is run and the value of its last form is returned. CONDITION is
+// a symbol or a list of symbols; the kinds error and t match any error.
+// Unhandled errors propagate unchanged.
+func SpecialFormConditionCase(args []Value, env *Environment, buffer *Buffer) (Value, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("condition-case expects at least 2 arguments, got %d", len(args))
+	}
+
+	if !isNilForm(args[0]) {
+		return nil, fmt.Errorf("condition-case expects nil as its first argument, got %v", args[0])
+	}
+
+	handlers := make([]*List, 0, len(args)-2)
+	for _, handler := range args[2:] {
+		list, ok := handler.(*List)
+		if !ok || list.IsEmpty() {
+			return nil, fmt.Errorf("condition-case handler must be a non-empty list, got %v", handler)
+		}
+		handlers = append(handlers, list)
+	}
+
+	result, err := evalExpression(args[1], env, buffer)
+	if err == nil {
+		return result, nil
+	}
+
+	kind := ErrorKind(err)
+	for _, handler := range handlers {
+		if conditionMatches(handler.First(), kind) {
+			return evalBody(handler.Rest().Elements, env, buffer)
+		}
+	}
+
+	return nil, err
+}
+
+// isNilForm reports whether value is written as nil, either directly or as an empty list.
+func isNilForm(value Value) bool {
+	if IsA(value, TheNullKind) {
+		return true
+	}
+	list, ok := value.(*List)
+	return ok && list.IsEmpty()
+}
+
+// conditionMatches reports whether a condition-case condition covers the error kind.
+func conditionMatches(condition Value, kind string) bool {
+	switch c := condition.(type) {
+	case *Symbol:
+		return c.Name == "error" || c.Name == kind
+	case *String:
+		return c.Value == "error" || c.Value == kind
+	case *Boolean:
+		return c.Value
+	case *List:
+		for _, element := range c.Elements {
+			if conditionMatches(element, kind) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func init() {
+	registerSpecialForm("condition-case", SpecialFormConditionCase)
+	RegisterDocumentation(FunctionDoc{
+		Name:        "condition-case",
+		Summary:     "Evaluate a form and handle its errors by kind",
+		Description: "Evaluates the protected form and returns its value. If the form signals an error, the first handler whose condition matches the kind of the error is evaluated instead and the value of its last form is returned. A handler is a list whose first element is the condition and whose remaining elements are forms. The condition is an error kind or a list of error kinds: search-failed, assertion-failed, undefined-function, or error (and t), which match any error. Errors without a matching handler abort the script as usual. The first argument must be nil because texted scripts have no variables; use progn to protect several forms at once.",
+		Category:    "control",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "var",
+				Type:        "nil",
+				Description: "Always nil; texted has no variables to bind the error to",
+				Optional:    false,
+			},
+			{
+				Name:        "bodyform",
+				Type:        "form",
+				Description: "Form to evaluate",
+				Optional:    false,
+			},
+			{
+				Name:        "handlers",
+				Type:        "list...",
+				Description: "Handlers of the form (CONDITION FORMS...)",
+				Optional:    true,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Insert a header when it is missing",
+				Input:       `condition-case nil (search-forward "// Header") (search-failed (insert "// Header\n"))`,
+				Buffer:      "package main",
+				Output:      "// Header\npackage main",
+			},
+			{
+				Description: "Handle several kinds of errors",
+				Input:       `(condition-case nil (progn (search-forward "x") (assert-looking-at "y")) ((search-failed assertion-failed) "skipped"))`,
+				Buffer:      "abc",
+				Output:      `Returns "skipped"`,
+			},
+		},
+		SeeAlso: []string{"ignore-errors", "progn", "error", "assert"},
+	})
+}
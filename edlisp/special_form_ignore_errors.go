@@ -0,0 +1,39 @@
+package edlisp
+
+// SpecialFormIgnoreErrors evaluates its forms like progn, but turns any error into nil.
+// Forms after the failing one are skipped; changes made before the error are kept.
+// Returns the value of the last form if all forms succeed.
+func SpecialFormIgnoreErrors(args []Value, env *Environment, buffer *Buffer) (Value, error) {
+	result, err := evalBody(args, env, buffer)
+	if err != nil {
+		return Nil, nil
+	}
+	return result, nil
+}
+
+func init() {
+	registerSpecialForm("ignore-errors", SpecialFormIgnoreErrors)
+	RegisterDocumentation(FunctionDoc{
+		Name:        "ignore-errors",
+		Summary:     "Evaluate forms, returning nil instead of failing",
+		Description: "Evaluates each form in order like progn. If a form signals an error, the remaining forms are skipped and ignore-errors returns nil instead of aborting the script. Changes made to the buffer before the error are kept. Returns the value of the last form if all forms succeed. Use it for optional steps, such as editing a header that not every file has.",
+		Category:    "control",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "forms",
+				Type:        "form...",
+				Description: "Forms to evaluate in order",
+				Optional:    true,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Skip a replacement when the text is missing",
+				Input:       `ignore-errors (search-forward "Copyright 2023") (replace-match "Copyright 2024"); end-of-buffer; insert "!"`,
+				Buffer:      "Hello world",
+				Output:      "Hello world!",
+			},
+		},
+		SeeAlso: []string{"condition-case", "progn"},
+	})
+}
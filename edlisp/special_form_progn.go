@@ -0,0 +1,36 @@
+package edlisp
+
+// SpecialFormProgn evaluates its forms in order and returns the value of the last one.
+// With no forms it returns nil. progn is mostly useful to group several steps
+// into a single form, for example as the body of ignore-errors or a
+// condition-case handler.
+func SpecialFormProgn(args []Value, env *Environment, buffer *Buffer) (Value, error) {
+	return evalBody(args, env, buffer)
+}
+
+func init() {
+	registerSpecialForm("progn", SpecialFormProgn)
+	RegisterDocumentation(FunctionDoc{
+		Name:        "progn",
+		Summary:     "Evaluate forms in order and return the last value",
+		Description: "Evaluates each form in order and returns the value of the last one, or nil if there are no forms. Evaluation stops at the first error. progn is a special form that groups several steps into a single form, for example as the protected form of condition-case.",
+		Category:    "control",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "forms",
+				Type:        "form...",
+				Description: "Forms to evaluate in order",
+				Optional:    true,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Group a search and a replacement",
+				Input:       `progn (search-forward "world") (replace-match "there")`,
+				Buffer:      "Hello world",
+				Output:      "Hello there",
+			},
+		},
+		SeeAlso: []string{"ignore-errors", "condition-case"},
+	})
+}
@@ -0,0 +1,47 @@
+package edlisp
+
+// SpecialFormSaveExcursion evaluates its forms like progn and restores point and mark afterwards.
+// Point and mark are saved as markers, so edits made by the body before or
+// inside the saved positions move them along with the surrounding text.
+// The current buffer is restored as well if the body switched buffers.
+// They are restored even if the body fails.
+func SpecialFormSaveExcursion(args []Value, env *Environment, buffer *Buffer) (Value, error) {
+	savedPoint := buffer.addMarker(buffer.Point())
+	savedMark := buffer.addMarker(buffer.Mark())
+	defer func() {
+		buffer.State().SetCurrent(buffer)
+		buffer.SetPoint(savedPoint.pos)
+		buffer.SetMark(savedMark.pos)
+		buffer.removeMarker(savedPoint)
+		buffer.removeMarker(savedMark)
+	}()
+
+	return evalBody(args, env, buffer)
+}
+
+func init() {
+	registerSpecialForm("save-excursion", SpecialFormSaveExcursion)
+	RegisterDocumentation(FunctionDoc{
+		Name:        "save-excursion",
+		Summary:     "Evaluate forms and restore point and mark afterwards",
+		Description: "Evaluates each form in order like progn and then restores point and mark to where they were before. Edits made inside the body are tracked: text inserted or deleted before the saved positions moves them, so point ends up next to the same text it was next to before. If the body switches to another buffer, the original buffer is made current again. Point, mark and the current buffer are restored even if a form fails. Returns the value of the last form.",
+		Category:    "control",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "forms",
+				Type:        "form...",
+				Description: "Forms to evaluate in order",
+				Optional:    true,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Add an import at the top and continue where we were",
+				Input:       `search-forward "func"; save-excursion (beginning-of-buffer) (insert "import \"fmt\"\n"); insert " main"`,
+				Buffer:      "func",
+				Output:      "import \"fmt\"\nfunc main",
+			},
+		},
+		SeeAlso: []string{"save-match-data", "progn", "point", "mark"},
+	})
+}
@@ -0,0 +1,44 @@
+package edlisp
+
+// SpecialFormSaveMatchData evaluates its forms like progn and restores the last search match afterwards.
+// This allows a script to run auxiliary searches without disturbing a later replace-match.
+// The match data is restored even if the body fails.
+func SpecialFormSaveMatchData(args []Value, env *Environment, buffer *Buffer) (Value, error) {
+	savedMatch := buffer.lastSearchMatch
+	savedStart := buffer.lastSearchStart
+	savedEnd := buffer.lastSearchEnd
+	defer func() {
+		buffer.lastSearchMatch = savedMatch
+		buffer.lastSearchStart = savedStart
+		buffer.lastSearchEnd = savedEnd
+	}()
+
+	return evalBody(args, env, buffer)
+}
+
+func init() {
+	registerSpecialForm("save-match-data", SpecialFormSaveMatchData)
+	RegisterDocumentation(FunctionDoc{
+		Name:        "save-match-data",
+		Summary:     "Evaluate forms and restore the last search match afterwards",
+		Description: "Evaluates each form in order like progn and then restores the match data of the last successful search, as used by replace-match. This allows auxiliary searches inside the body without disturbing a replacement that follows. The match data is restored even if a form fails. Returns the value of the last form.",
+		Category:    "control",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "forms",
+				Type:        "form...",
+				Description: "Forms to evaluate in order",
+				Optional:    true,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Check the surroundings of a match before replacing it",
+				Input:       `search-forward "old"; save-match-data (save-excursion (search-forward "end")); replace-match "new"`,
+				Buffer:      "old ... end",
+				Output:      "new ... end",
+			},
+		},
+		SeeAlso: []string{"save-excursion", "replace-match", "search-forward"},
+	})
+}
@@ -0,0 +1,57 @@
+package edlisp
+
+// SpecialFormSaveRestriction evaluates its forms like progn and restores the narrowing afterwards.
+// If the buffer was narrowed, the same accessible portion is restored, adjusted
+// for edits made by the body. If it was not narrowed, it is widened again.
+// The restriction is restored even if the body fails.
+func SpecialFormSaveRestriction(args []Value, env *Environment, buffer *Buffer) (Value, error) {
+	saved := buffer.restriction
+	if saved != nil {
+		// Keep the saved bounds up to date while the body narrows or widens.
+		saved = &restriction{
+			start: buffer.addMarker(saved.start.pos),
+			end:   &marker{pos: saved.end.pos, advance: true},
+		}
+		buffer.markers = append(buffer.markers, saved.end)
+	}
+	defer func() {
+		if saved == nil {
+			buffer.Widen()
+			return
+		}
+		buffer.removeMarker(saved.start)
+		buffer.removeMarker(saved.end)
+		point := buffer.Point()
+		buffer.Narrow(saved.start.pos, saved.end.pos)
+		buffer.SetPoint(point)
+	}()
+
+	return evalBody(args, env, buffer)
+}
+
+func init() {
+	registerSpecialForm("save-restriction", SpecialFormSaveRestriction)
+	RegisterDocumentation(FunctionDoc{
+		Name:        "save-restriction",
+		Summary:     "Evaluate forms and restore the narrowing afterwards",
+		Description: "Evaluates each form in order like progn and then restores the narrowing that was in effect before. If the buffer was narrowed, the same text is accessible afterwards, even if the body inserted or deleted text. If it was not narrowed, it is widened again. The restriction is restored even if a form fails. Point is not restored; combine with save-excursion for that. Returns the value of the last form.",
+		Category:    "narrowing",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "forms",
+				Type:        "form...",
+				Description: "Forms to evaluate in order",
+				Optional:    true,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Edit only the first line, then continue on the whole buffer",
+				Input:       `save-restriction (narrow-to-region 1 4) (end-of-buffer) (insert "!"); end-of-buffer; insert "?"`,
+				Buffer:      "abc\ndef",
+				Output:      "abc!\ndef?",
+			},
+		},
+		SeeAlso: []string{"narrow-to-region", "widen", "save-excursion"},
+	})
+}
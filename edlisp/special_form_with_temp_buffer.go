@@ -0,0 +1,44 @@
+package edlisp
+
+// SpecialFormWithTempBuffer evaluates its forms like progn in a new, empty buffer.
+// The temporary buffer is current while the body runs and is discarded
+// afterwards, even if the body fails. The previously current buffer is
+// current again afterwards.
+func SpecialFormWithTempBuffer(args []Value, env *Environment, buffer *Buffer) (Value, error) {
+	state := buffer.State()
+	temp := state.GetBufferCreate(state.generateBufferName(" *temp*"))
+	state.SetCurrent(temp)
+	defer func() {
+		state.killBuffer(temp)
+		state.SetCurrent(buffer)
+	}()
+
+	return evalBody(args, env, temp)
+}
+
+func init() {
+	registerSpecialForm("with-temp-buffer", SpecialFormWithTempBuffer)
+	RegisterDocumentation(FunctionDoc{
+		Name:        "with-temp-buffer",
+		Summary:     "Evaluate forms in a new, empty buffer",
+		Description: "Creates a new, empty buffer, makes it current and evaluates each form in order like progn. Afterwards the temporary buffer is discarded and the previously current buffer is current again, even if a form fails. Returns the value of the last form, so ending the body with buffer-string returns the text built in the temporary buffer.",
+		Category:    "buffer",
+		Parameters: []ParameterDoc{
+			{
+				Name:        "forms",
+				Type:        "form...",
+				Description: "Forms to evaluate in the temporary buffer",
+				Optional:    true,
+			},
+		},
+		Examples: []ExampleDoc{
+			{
+				Description: "Build text in a scratch area and insert it",
+				Input:       `insert (with-temp-buffer (insert "b") (beginning-of-buffer) (insert "a") (buffer-string))`,
+				Buffer:      "",
+				Output:      "ab",
+			},
+		},
+		SeeAlso: []string{"get-buffer-create", "set-buffer", "buffer-string", "progn"},
+	})
+}
@@ -0,0 +1,144 @@
+package edlisp
+
+import (
+	"fmt"
+)
+
+// MainBufferName is the name given to the buffer an evaluation starts in
+// unless it has been named otherwise.
+const MainBufferName = "*main*"
+
+// State holds the named buffers of an evaluation and tracks which of them is current.
+//
+// Every buffer belongs to at most one state. Eval creates a state for the
+// buffer it is given if there is none yet, so callers only need to create one
+// themselves to preload additional buffers or to read them back afterwards:
+//
+//	state := NewState(NewBuffer(input))
+//	state.AddBuffer("template", NewBuffer(template))
+//	_, err := Eval(program, env, state.Current())
+//	for _, buffer := range state.Buffers() { ... }
+type State struct {
+	buffers []*Buffer
+	current *Buffer
+	env     *Environment
+
+	// loading lists the script files being loaded, innermost last.
+	loading []string
+}
+
+// NewState creates a state containing buffer and makes it the current buffer.
+// An unnamed buffer is registered as MainBufferName.
+func NewState(buffer *Buffer) *State {
+	s := &State{}
+	if buffer.name == "" {
+		buffer.name = MainBufferName
+	}
+	s.attach(buffer)
+	s.current = buffer
+	return s
+}
+
+// AddBuffer registers buffer under name.
+// It fails if the state already has a buffer with that name or if buffer
+// already belongs to another state.
+func (s *State) AddBuffer(name string, buffer *Buffer) error {
+	if s.GetBuffer(name) != nil {
+		return fmt.Errorf("buffer %q already exists", name)
+	}
+	if buffer.state != nil {
+		return fmt.Errorf("buffer %q already belongs to an evaluation", buffer.name)
+	}
+	buffer.name = name
+	s.attach(buffer)
+	return nil
+}
+
+// GetBuffer returns the buffer called name, or nil if there is none.
+func (s *State) GetBuffer(name string) *Buffer {
+	for _, buffer := range s.buffers {
+		if buffer.name == name {
+			return buffer
+		}
+	}
+	return nil
+}
+
+// GetBufferCreate returns the buffer called name, creating an empty one if necessary.
+func (s *State) GetBufferCreate(name string) *Buffer {
+	if buffer := s.GetBuffer(name); buffer != nil {
+		return buffer
+	}
+	buffer := NewBuffer("")
+	buffer.name = name
+	s.attach(buffer)
+	return buffer
+}
+
+// Buffers returns all buffers of the state in the order they were added.
+func (s *State) Buffers() []*Buffer {
+	return append([]*Buffer(nil), s.buffers...)
+}
+
+// Current returns the buffer that builtins operate on.
+func (s *State) Current() *Buffer {
+	return s.current
+}
+
+// SetCurrent makes buffer the current buffer. The buffer must belong to s.
+func (s *State) SetCurrent(buffer *Buffer) error {
+	if buffer.state != s {
+		return fmt.Errorf("buffer %q does not belong to this evaluation", buffer.name)
+	}
+	s.current = buffer
+	return nil
+}
+
+// generateBufferName returns base if no buffer has that name yet,
+// and otherwise base followed by the first free suffix <2>, <3>, ...
+func (s *State) generateBufferName(base string) string {
+	name := base
+	for i := 2; s.GetBuffer(name) != nil; i++ {
+		name = fmt.Sprintf("%s<%d>", base, i)
+	}
+	return name
+}
+
+// killBuffer removes buffer from the state.
+// If it was current, the first remaining buffer becomes current.
+func (s *State) killBuffer(buffer *Buffer) {
+	for i, b := range s.buffers {
+		if b == buffer {
+			s.buffers = append(s.buffers[:i], s.buffers[i+1:]...)
+			break
+		}
+	}
+	buffer.state = nil
+	if s.current == buffer && len(s.buffers) > 0 {
+		s.current = s.buffers[0]
+	}
+}
+
+func (s *State) attach(buffer *Buffer) {
+	buffer.state = s
+	s.buffers = append(s.buffers, buffer)
+}
+
+// Name returns the name of the buffer, or "" if it has not been added to a state yet.
+func (b *Buffer) Name() string {
+	return b.name
+}
+
+// State returns the evaluation state the buffer belongs to, creating one if necessary.
+func (b *Buffer) State() *State {
+	if b.state == nil {
+		NewState(b)
+	}
+	return b.state
+}
+
+// current returns the buffer that is current in the state of b.
+// The evaluator uses this to follow set-buffer and with-temp-buffer.
+func (b *Buffer) current() *Buffer {
+	return b.State().Current()
+}
@@ -20,6 +20,9 @@ type TestCase struct {
 	Output string `xml:"output"`
 	Result Result `xml:"result"`
 	Error  Error  `xml:"error"`
+
+	// Messages is the expected text printed by message and princ.
+	Messages string `xml:"messages"`
 }
 
 // Input represents the test input with language specification.
@@ -60,12 +63,13 @@ func ParseTestCase(r io.Reader) (*TestCase, error) {
 	wrappedXML := "<testcase>" + string(content) + "</testcase>"
 
 	var wrapper struct {
-		XMLName xml.Name `xml:"testcase"`
-		Buffer  string   `xml:"buffer"`
-		Input   Input    `xml:"input"`
-		Output  string   `xml:"output"`
-		Result  Result   `xml:"result"`
-		Error   Error    `xml:"error"`
+		XMLName  xml.Name `xml:"testcase"`
+		Buffer   string   `xml:"buffer"`
+		Input    Input    `xml:"input"`
+		Output   string   `xml:"output"`
+		Result   Result   `xml:"result"`
+		Error    Error    `xml:"error"`
+		Messages string   `xml:"messages"`
 	}
 
 	err = xml.Unmarshal([]byte(wrappedXML), &wrapper)
@@ -74,11 +78,12 @@ func ParseTestCase(r io.Reader) (*TestCase, error) {
 	}
 
 	return &TestCase{
-		Buffer: wrapper.Buffer,
-		Input:  wrapper.Input,
-		Output: wrapper.Output,
-		Result: wrapper.Result,
-		Error:  wrapper.Error,
+		Buffer:   wrapper.Buffer,
+		Input:    wrapper.Input,
+		Output:   wrapper.Output,
+		Result:   wrapper.Result,
+		Error:    wrapper.Error,
+		Messages: wrapper.Messages,
 	}, nil
 }
 
@@ -121,6 +126,12 @@ func RunTestWithTrace(testCase *TestCase, env *edlisp.Environment, traceCallback
 		}
 	}
 
+	// Capture the output of message and princ
+	var messages edlisp.CapturedOutput
+	previousOutput := env.Output
+	env.Output = &messages
+	defer func() { env.Output = previousOutput }()
+
 	// Execute the program
 	evalResult, evalErr := edlisp.EvalWithTrace(program, env, buffer, traceCallback)
 
@@ -149,12 +160,26 @@ func RunTestWithTrace(testCase *TestCase, env *edlisp.Environment, traceCallback
 	hasOutputExpectation := testCase.Output != ""
 	expectedResult := strings.TrimSpace(testCase.Result.Text)
 	hasResultExpectation := expectedResult != ""
+	hasMessagesExpectation := testCase.Messages != ""
 
-	if !hasOutputExpectation && !hasResultExpectation {
-		result.Error = fmt.Errorf("test case must specify at least one of <output> or <result> elements")
+	if !hasOutputExpectation && !hasResultExpectation && !hasMessagesExpectation {
+		result.Error = fmt.Errorf("test case must specify at least one of <output>, <result> or <messages> elements")
 		return result
 	}
 
+	// Check messages if specified
+	if hasMessagesExpectation {
+		expected := testCase.Messages
+		actual := messages.String()
+
+		if expected != actual {
+			result.Expected = expected
+			result.Actual = actual
+			result.Error = fmt.Errorf("messages mismatch:\nexpected: %q\nactual: %q", expected, actual)
+			return result
+		}
+	}
+
 	// Check output if specified
 	if hasOutputExpectation {
 		expected := testCase.Output
@@ -55,6 +55,10 @@ func (w *JSONWriter) valueToJSON(value edlisp.Value) (interface{}, error) {
 		return v.Value, nil
 	case *edlisp.Number:
 		return v.Value, nil
+	case *edlisp.Boolean:
+		return v.Value, nil
+	case *edlisp.Null:
+		return nil, nil
 	default:
 		return nil, fmt.Errorf("unsupported value type for JSON: %T", value)
 	}
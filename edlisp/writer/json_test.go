@@ -70,6 +70,34 @@ func TestJSONWriter_WriteValue_Number(t *testing.T) {
 	}
 }
 
+func TestJSONWriter_WriteValue_Boolean(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    edlisp.Value
+		expected string
+	}{
+		{"t", edlisp.T, "true\n"},
+		{"false", edlisp.NewBoolean(false), "false\n"},
+		{"nil", edlisp.Nil, "null\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &JSONWriter{}
+			var buf bytes.Buffer
+
+			err := writer.WriteValue(&buf, tt.value)
+			if err != nil {
+				t.Fatalf("WriteValue failed: %v", err)
+			}
+
+			if buf.String() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
 func TestJSONWriter_WriteValue_List(t *testing.T) {
 	tests := []struct {
 		name     string
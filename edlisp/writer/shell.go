@@ -67,6 +67,10 @@ func (w *ShellWriter) valueToToken(value edlisp.Value) (string, error) {
 		return strconv.Quote(v.Value), nil
 	case *edlisp.Number:
 		return fmt.Sprintf("%v", v), nil
+	case *edlisp.Boolean:
+		return v.String(), nil
+	case *edlisp.Null:
+		return v.String(), nil
 	case *edlisp.List:
 		return "", fmt.Errorf("nested lists are not supported in shell format")
 	default:
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package texted
+
+import (
+	"io/fs"
+	"os"
+)
+
+// copyOwner does nothing on systems without Unix file ownership.
+func copyOwner(f *os.File, original fs.FileInfo) {}
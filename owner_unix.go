@@ -0,0 +1,21 @@
+//go:build unix
+
+package texted
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// copyOwner gives f the owner and group of original. Only root may give
+// files away, so other users keep at least the group if they belong to it.
+func copyOwner(f *os.File, original fs.FileInfo) {
+	stat, ok := original.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+	if err := f.Chown(int(stat.Uid), int(stat.Gid)); err != nil {
+		f.Chown(-1, int(stat.Gid))
+	}
+}
@@ -0,0 +1,42 @@
+package texted
+
+import (
+	"runtime"
+)
+
+// jobs returns the number of files to edit at the same time.
+func (o Options) jobs() int {
+	if o.Jobs > 0 {
+		return o.Jobs
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// InOrder calls work for the items 0 to n-1 on up to jobs goroutines and
+// hands each result to done, one at a time and in order of the items.
+// At most jobs items are in flight, counting those that are finished but
+// waiting for an earlier item, so memory stays bounded however many items there are.
+// It is how Options.EditFiles edits files in parallel, for callers that
+// process files in other ways.
+func InOrder[T any](n, jobs int, work func(i int) T, done func(i int, result T)) {
+	jobs = max(jobs, 1)
+	results := make([]chan T, n)
+	for i := range results {
+		results[i] = make(chan T, 1)
+	}
+
+	slots := make(chan struct{}, jobs)
+	go func() {
+		for i := range n {
+			slots <- struct{}{}
+			go func() {
+				results[i] <- work(i)
+			}()
+		}
+	}()
+
+	for i := range n {
+		done(i, <-results[i])
+		<-slots
+	}
+}
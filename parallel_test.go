@@ -0,0 +1,92 @@
+package texted
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dhamidi/texted/edlisp"
+)
+
+func TestEditFilesWithOptions_Jobs(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for i := range 50 {
+		filename := filepath.Join(dir, fmt.Sprintf("file%02d.txt", i))
+		if err := os.WriteFile(filename, []byte(fmt.Sprintf("file %d\n", i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, filename)
+	}
+	files = append(files, filepath.Join(dir, "missing.txt"))
+
+	var messages edlisp.CapturedOutput
+	results, err := EditFilesWithOptions(files, `search-forward "file"; replace-match "line"; message "%s" (buffer-string)`, Options{
+		Jobs:   8,
+		Output: &messages,
+	})
+	if err != nil {
+		t.Fatalf("EditFilesWithOptions() error = %v", err)
+	}
+
+	for i, result := range results[:50] {
+		if result.Filename != files[i] || !result.Success {
+			t.Errorf("results[%d] = %+v, want success for %s", i, result, files[i])
+		}
+		if want := fmt.Sprintf("line %d\n\n", i); messages.Entries[i] != want {
+			t.Errorf("message %d = %q, want %q", i, messages.Entries[i], want)
+		}
+	}
+	if last := results[50]; last.Success || last.Error == nil {
+		t.Errorf("missing file should fail, got %+v", last)
+	}
+}
+
+func TestEditFilesWithOptions_DuplicateFiles(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "d.txt")
+	if err := os.WriteFile(filename, []byte("abc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	same := filepath.Join(filepath.Dir(filename), ".", "d.txt")
+	files := []string{filename, same, filename, filename}
+
+	for _, atomic := range []bool{false, true} {
+		results, err := EditFilesWithOptions(files, `insert "x"`, Options{Jobs: 4, Atomic: atomic})
+		if err != nil {
+			t.Fatalf("EditFilesWithOptions() error = %v", err)
+		}
+		for i, result := range results {
+			if result.Filename != files[i] || !result.Success {
+				t.Errorf("atomic=%v: results[%d] = %+v, want success for %s", atomic, i, result, files[i])
+			}
+		}
+	}
+	if content, _ := os.ReadFile(filename); string(content) != "xxabc" {
+		t.Errorf("content = %q, want one edit per call", content)
+	}
+}
+
+func TestInOrder(t *testing.T) {
+	var got []int
+	InOrder(20, 4, func(i int) int {
+		// Later items finish first
+		time.Sleep(time.Duration(20-i) * time.Millisecond)
+		return i * i
+	}, func(i int, result int) {
+		if result != i*i {
+			t.Errorf("result of item %d = %d, want %d", i, result, i*i)
+		}
+		got = append(got, i)
+	})
+
+	for i, item := range got {
+		if item != i {
+			t.Fatalf("items handled in order %v", got)
+		}
+	}
+	if len(got) != 20 {
+		t.Errorf("handled %d items, want 20", len(got))
+	}
+}
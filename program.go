@@ -0,0 +1,115 @@
+package texted
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/dhamidi/texted/edlisp"
+)
+
+// Program is a script parsed once so it can run on many inputs.
+// A Program is not modified by running it, so one Program may be run
+// on several goroutines at once.
+type Program struct {
+	values []edlisp.Value
+	each   *regexp.Regexp
+	opts   Options
+
+	// env is the environment every run evaluates the script in, apart
+	// from where the output goes. Evaluation does not change it.
+	env *edlisp.Environment
+}
+
+// Compile parses script as configured by o and binds its arguments.
+// It fails like Parse, or if o.Each is not a valid regular expression.
+func (o Options) Compile(script string) (*Program, error) {
+	var each *regexp.Regexp
+	if o.Each != "" {
+		re, err := regexp.Compile(o.Each)
+		if err != nil {
+			return nil, fmt.Errorf("invalid each regexp: %w", err)
+		}
+		each = re
+	}
+
+	values, err := o.Parse(script)
+	if err != nil {
+		return nil, err
+	}
+	// Plugins that cannot be used are reported before the program runs
+	env, err := o.NewEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Program{values: values, each: each, opts: o, env: env}, nil
+}
+
+// Run executes the program on input in a fresh environment and returns the
+// modified input. If Options.Each is set, it also returns the match counts.
+// The script sees input as UTF-8 with lines ending in \n and without a byte
+// order mark; the result is converted back to the encoding and line endings
+// of input, or as changed by Options.EOL or the script. Run fails if the
+// encoding cannot represent the edited text.
+func (p *Program) Run(input string) (string, *edlisp.EachResult, error) {
+	return p.RunWithOutput(input, p.opts.Output)
+}
+
+// RunWithOutput is like Run, but the text printed with message and princ
+// goes to output instead of Options.Output.
+func (p *Program) RunWithOutput(input string, output edlisp.OutputSink) (string, *edlisp.EachResult, error) {
+	result, err := p.Execute(input, output)
+	if err != nil {
+		return "", nil, err
+	}
+	return result.Output, result.Matches, nil
+}
+
+// RunResult is what running a Program on one input produced.
+type RunResult struct {
+	// Output is the modified input.
+	Output string
+
+	// Value is the value of the last expression of the script.
+	// It is nil if Options.Each is set.
+	Value edlisp.Value
+
+	// Point and Mark are their positions in the buffer after the script ran.
+	Point, Mark int
+
+	// Matches counts the matches the script ran at if Options.Each is set.
+	Matches *edlisp.EachResult
+}
+
+// Execute is like RunWithOutput, but also returns the final state of the
+// buffer and the value of the script. If the script fails, the error wraps
+// an *edlisp.ExecutionError describing the state at the failure.
+func (p *Program) Execute(input string, output edlisp.OutputSink) (*RunResult, error) {
+	env := *p.env
+	env.Output = output
+	return p.run(&env, p.opts.NewBuffer(input))
+}
+
+// run executes the program on buf in env. Evaluation does not change
+// env, so it can be shared by many runs.
+func (p *Program) run(env *edlisp.Environment, buf *edlisp.Buffer) (*RunResult, error) {
+	result := &RunResult{}
+	if p.each != nil {
+		matches := edlisp.EvalEach(p.values, env, buf, p.each)
+		result.Matches = &matches
+	} else {
+		value, err := edlisp.Eval(p.values, env, buf)
+		if err != nil {
+			return nil, fmt.Errorf("script execution failed: %w", err)
+		}
+		result.Value = value
+	}
+	result.Point, result.Mark = buf.Point(), buf.Mark()
+
+	text, err := buf.Coding().Encode(buf.String())
+	if err != nil {
+		return nil, fmt.Errorf("saving buffer: %w", err)
+	}
+	result.Output = text
+	return result, nil
+}
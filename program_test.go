@@ -0,0 +1,146 @@
+package texted
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dhamidi/texted/edlisp"
+)
+
+func TestProgramRun_LineEndings(t *testing.T) {
+	script := `end-of-line; insert "!"; goto-line 2; end-of-line; insert "?"`
+
+	tests := []struct {
+		name  string
+		input string
+		eol   edlisp.EOLType
+		want  string
+	}{
+		{name: "dos is preserved", input: "one\r\ntwo\r\n", want: "one!\r\ntwo?\r\n"},
+		{name: "bom is preserved", input: "\xef\xbb\xbfone\ntwo\n", want: "\xef\xbb\xbfone!\ntwo?\n"},
+		{name: "converted to unix", input: "one\r\ntwo\r\n", eol: edlisp.EOLUnix, want: "one!\ntwo?\n"},
+		{name: "converted to dos", input: "one\ntwo\n", eol: edlisp.EOLDOS, want: "one!\r\ntwo?\r\n"},
+		{name: "mixed converted to unix", input: "one\r\ntwo\rthree\n", eol: edlisp.EOLUnix, want: "one!\ntwo?\nthree\n"},
+		{name: "mixed converted to dos", input: "one\r\ntwo\rthree\n", eol: edlisp.EOLDOS, want: "one!\r\ntwo?\r\nthree\r\n"},
+		{name: "mixed converted to mac", input: "one\r\ntwo\rthree\n", eol: edlisp.EOLMac, want: "one!\rtwo?\rthree\r"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExecuteScriptWithOptions(tt.input, script, Options{EOL: tt.eol})
+			if err != nil {
+				t.Fatalf("ExecuteScriptWithOptions() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ExecuteScriptWithOptions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	got, err := ExecuteScriptWithOptions("a\r\n", `buffer-eol-type`, Options{})
+	if err != nil || got != "a\r\n" {
+		t.Errorf("buffer-eol-type changed the text: %q, %v", got, err)
+	}
+}
+
+func TestProgramRun_Encodings(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		script   string
+		encoding edlisp.Encoding
+		want     string
+		wantErr  bool
+	}{
+		{name: "iso-8859-1 is preserved", input: "k=Jos\xe9\n", script: `end-of-buffer; insert "ü=ü"`, want: "k=Jos\xe9\n\xfc=\xfc"},
+		{name: "utf-16le is preserved", input: "\xff\xfeo\x00k\x00", script: `end-of-buffer; insert "!"`, want: "\xff\xfeo\x00k\x00!\x00"},
+		{name: "read as iso-8859-1", input: "\xc3\xa9", script: `insert "é"`, encoding: edlisp.EncodingLatin1, want: "\xe9\xc3\xa9"},
+		{name: "converted to utf-8", input: "\x93Jos\xe9\x94", script: `set-buffer-encoding "utf-8"`, want: "“José”"},
+		{name: "not representable", input: "caf\xe9", script: `insert "€"`, encoding: edlisp.EncodingLatin1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExecuteScriptWithOptions(tt.input, tt.script, Options{Encoding: tt.encoding})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ExecuteScriptWithOptions() = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExecuteScriptWithOptions() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ExecuteScriptWithOptions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgramExecute(t *testing.T) {
+	program, err := Options{}.Compile(`goto-line 2; set-mark; end-of-line; buffer-substring (mark) (point)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := program.Execute("one\ntwo\n", &edlisp.CapturedOutput{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Output != "one\ntwo\n" || result.Point != 8 || result.Mark != 5 {
+		t.Errorf("Execute() = %+v, want the input with point 8 and mark 5", result)
+	}
+	if s, ok := result.Value.(*edlisp.String); !ok || s.Value != "two" {
+		t.Errorf("Execute() value = %v, want \"two\"", result.Value)
+	}
+
+	program, err = Options{}.Compile(`goto-line 2; search-forward "é"; search-forward "x"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = program.Execute("one\nnaïve é\n", &edlisp.CapturedOutput{})
+	var execErr *edlisp.ExecutionError
+	if !errors.As(err, &execErr) {
+		t.Fatalf("Execute() error = %v, want an *edlisp.ExecutionError", err)
+	}
+	if line, column := execErr.Position(); line != 2 || column != 8 {
+		t.Errorf("Position() = %d:%d, want 2:8", line, column)
+	}
+}
+
+func TestOptionsCompile_PluginNameTaken(t *testing.T) {
+	plugin := &edlisp.Plugin{Path: "texted-fn-insert", Doc: edlisp.FunctionDoc{Name: "insert"}}
+	_, err := Options{Plugins: []*edlisp.Plugin{plugin}}.Compile(`insert "x"`)
+	if err == nil || !strings.Contains(err.Error(), "registering plugin texted-fn-insert") {
+		t.Errorf("Compile() error = %v, want the plugin to be rejected", err)
+	}
+}
+
+func TestProgramExecute_Output(t *testing.T) {
+	var options edlisp.CapturedOutput
+	program, err := Options{Output: &options}.Compile(`message "%s" (buffer-string)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var first, second edlisp.CapturedOutput
+	if _, err := program.Execute("one", &first); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := program.Execute("two", &second); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := program.Run("three"); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(first.Entries, ""); got != "one\n" {
+		t.Errorf("first run printed %q, want %q", got, "one\n")
+	}
+	if got := strings.Join(second.Entries, ""); got != "two\n" {
+		t.Errorf("second run printed %q, want %q", got, "two\n")
+	}
+	if got := strings.Join(options.Entries, ""); got != "three\n" {
+		t.Errorf("Run printed %q to Options.Output, want %q", got, "three\n")
+	}
+}
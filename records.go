@@ -0,0 +1,176 @@
+package texted
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/dhamidi/texted/edlisp"
+)
+
+// MaxRecordSize is the length in bytes of the longest record RunRecords
+// accepts, including its separator. It bounds the memory used for a record.
+const MaxRecordSize = 16 << 20
+
+// RecordSplit says how RunRecords splits its input into records.
+// The zero value splits lines: records end with \n or \r\n.
+type RecordSplit struct {
+	// Null ends records with NUL characters instead of newlines, for the
+	// output of find -print0 and similar tools.
+	Null bool
+
+	// Separator ends records with the matches of a regular expression
+	// instead. It is matched against the input following the previous
+	// record, so ^ matches at the start of each record. Separators that
+	// could grow by more input are only taken once the input shows they
+	// are complete.
+	Separator *regexp.Regexp
+}
+
+// RunRecords reads records from r, runs the program on each record in a
+// fresh buffer and writes the results to w as they are ready, each followed
+// by the separator it was read with. Only one record is kept in memory at a
+// time, so RunRecords can filter unbounded input. Output is flushed whenever
+// reading r would block, to keep pipelines responsive.
+//
+// The buffer holds the record without its separator, converted as described
+// for Run, except that the encoding is detected once, from the first chunk
+// of input, so that all records are read alike. Records are split before
+// they are decoded, so UTF-16 input is rejected. RunRecords stops at the
+// first record the script fails on, after writing the results of the
+// records before it.
+func (p *Program) RunRecords(r io.Reader, w io.Writer, split RecordSplit) error {
+	out := bufio.NewWriter(w)
+	in := bufio.NewReader(&flushingReader{r: r, w: out})
+	opts := p.opts
+	encoding, err := opts.recordEncoding(in, split)
+	if err != nil {
+		return err
+	}
+	opts.Encoding = encoding
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxRecordSize)
+	// sep is the length of the separator ending the last token
+	var sep int
+	scanner.Split(split.splitFunc(&sep))
+
+	n := 0
+	for scanner.Scan() {
+		n++
+		token := scanner.Bytes()
+		record, separator := token[:len(token)-sep], token[len(token)-sep:]
+
+		result, err := p.run(p.env, opts.NewBuffer(string(record)))
+		if err != nil {
+			out.Flush()
+			return fmt.Errorf("record %d: %w", n, err)
+		}
+		out.WriteString(result.Output)
+		out.Write(separator)
+	}
+	if err := scanner.Err(); err != nil {
+		out.Flush()
+		if errors.Is(err, bufio.ErrTooLong) {
+			return fmt.Errorf("record %d is longer than %d bytes", n+1, MaxRecordSize)
+		}
+		return err
+	}
+	return out.Flush()
+}
+
+// recordEncoding returns o.Encoding, or if it is empty the encoding
+// edlisp.DetectEncoding finds in the first chunk of input read into in.
+// It fails for the UTF-16 encodings, whose text cannot be split at the
+// bytes of ASCII separators.
+func (o Options) recordEncoding(in *bufio.Reader, split RecordSplit) (edlisp.Encoding, error) {
+	encoding := o.Encoding
+	if encoding == "" {
+		// Peeking at one byte waits for the first read, but not for more input
+		in.Peek(1)
+		chunk, _ := in.Peek(in.Buffered())
+		// Cutting at a line ending or NUL never splits a character; NUL
+		// separators would make the text look like UTF-16.
+		if i := bytes.LastIndexAny(chunk, "\n\x00"); i >= 0 {
+			chunk = chunk[:i+1]
+		}
+		if split.Null {
+			chunk = bytes.ReplaceAll(chunk, []byte{0}, nil)
+		}
+		encoding = edlisp.DetectEncoding(string(chunk))
+	}
+	switch encoding {
+	case edlisp.EncodingUTF16LE, edlisp.EncodingUTF16BE:
+		return "", fmt.Errorf("records cannot be split in %s input", encoding)
+	}
+	return encoding, nil
+}
+
+// splitFunc returns a bufio.SplitFunc producing records followed by their
+// separators, storing the length of the separator of each token in sep.
+// The last record of the input may have no separator.
+func (s RecordSplit) splitFunc(sep *int) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		start, end := s.find(data, atEOF)
+		if start >= 0 {
+			if start == end {
+				return 0, nil, fmt.Errorf("record separator %q matches the empty string", s.Separator)
+			}
+			*sep = end - start
+			return end, data[:end], nil
+		}
+		if atEOF && len(data) > 0 {
+			*sep = 0
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+// find returns the position of the first complete separator in data, or
+// -1, -1 if there is none yet.
+func (s RecordSplit) find(data []byte, atEOF bool) (int, int) {
+	switch {
+	case s.Separator != nil:
+		loc := s.Separator.FindIndex(data)
+		if loc == nil || loc[1] == len(data) && !atEOF {
+			return -1, -1
+		}
+		return loc[0], loc[1]
+	case s.Null:
+		i := bytes.IndexByte(data, 0)
+		if i < 0 {
+			return -1, -1
+		}
+		return i, i + 1
+	default:
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			return -1, -1
+		}
+		if i > 0 && data[i-1] == '\r' {
+			return i - 1, i + 1
+		}
+		return i, i + 1
+	}
+}
+
+// flushingReader flushes w before every read from r, so that the output
+// written so far is passed on before waiting for more input.
+type flushingReader struct {
+	r io.Reader
+	w *bufio.Writer
+}
+
+func (f *flushingReader) Read(p []byte) (int, error) {
+	if err := f.w.Flush(); err != nil {
+		return 0, err
+	}
+	return f.r.Read(p)
+}
@@ -0,0 +1,178 @@
+package texted
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/dhamidi/texted/edlisp"
+)
+
+func TestProgramRunRecords(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		each   string
+		split  RecordSplit
+		input  string
+		want   string
+	}{
+		{
+			name:   "lines keep their line endings",
+			script: `end-of-line; insert "!"`,
+			input:  "one\r\ntwo\n\nlast",
+			want:   "one!\r\ntwo!\n!\nlast!",
+		},
+		{
+			name:   "nul separated",
+			script: `insert "> "`,
+			split:  RecordSplit{Null: true},
+			input:  "a b\x00c\nd\x00",
+			want:   "> a b\x00> c\nd\x00",
+		},
+		{
+			name:   "regexp separated",
+			script: `insert "["; end-of-buffer; insert "]"`,
+			split:  RecordSplit{Separator: regexp.MustCompile(`\n\n+`)},
+			input:  "a\nb\n\n\nc\n",
+			want:   "[a\nb]\n\n\n[c\n]",
+		},
+		{
+			name:   "each",
+			script: `replace-match "X"`,
+			each:   "o",
+			input:  "foo\nbar\n",
+			want:   "fXX\nbar\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Options{Each: tt.each}.Compile(tt.script)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out strings.Builder
+			if err := program.RunRecords(strings.NewReader(tt.input), &out, tt.split); err != nil {
+				t.Fatalf("RunRecords() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("RunRecords() wrote %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestProgramRunRecords_Errors(t *testing.T) {
+	program, err := Options{}.Compile(`search-forward "a"; insert "!"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	err = program.RunRecords(strings.NewReader("a\nb\na\n"), &out, RecordSplit{})
+	if err == nil || !strings.HasPrefix(err.Error(), "record 2: ") {
+		t.Errorf("RunRecords() error = %v, want an error for record 2", err)
+	}
+	if out.String() != "a!\n" {
+		t.Errorf("RunRecords() wrote %q, want the records before the error", out.String())
+	}
+
+	empty := RecordSplit{Separator: regexp.MustCompile(`x*`)}
+	if err := program.RunRecords(strings.NewReader("a\n"), io.Discard, empty); err == nil {
+		t.Error("RunRecords() with a separator matching the empty string should fail")
+	}
+}
+
+// lineReader returns one line per read and records what was written before each read.
+type lineReader struct {
+	lines   []string
+	out     *bytes.Buffer
+	written []string
+}
+
+func (r *lineReader) Read(p []byte) (int, error) {
+	r.written = append(r.written, r.out.String())
+	if len(r.lines) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.lines[0])
+	r.lines = r.lines[1:]
+	return n, nil
+}
+
+func TestProgramRunRecords_Streams(t *testing.T) {
+	program, err := Options{}.Compile(`insert "> "`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	in := &lineReader{lines: []string{"one\n", "two\n"}, out: &out}
+	if err := program.RunRecords(in, &out, RecordSplit{}); err != nil {
+		t.Fatalf("RunRecords() error = %v", err)
+	}
+	if in.written[1] != "> one\n" {
+		t.Errorf("before reading the second line, RunRecords() wrote %q, want the first record", in.written[1])
+	}
+}
+
+func TestProgramRunRecords_Encodings(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding edlisp.Encoding
+		input    string
+		want     string
+	}{
+		{name: "a latin-1 line makes the stream latin-1", input: "abc\ncaf\xe9\n", want: "abc\xfc\ncaf\xe9\xfc\n"},
+		{name: "latin-1 without a line ending", input: "caf\xe9", want: "caf\xe9\xfc"},
+		{name: "latin-1 stream with a utf-8 line", input: "caf\xe9\nna\xc3\xafve\n", want: "caf\xe9\xfc\nna\xc3\xafve\xfc\n"},
+		{name: "explicit latin-1", encoding: edlisp.EncodingLatin1, input: "abc\n", want: "abc\xfc\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Options{Encoding: tt.encoding}.Compile(`end-of-line; insert "ü"`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out strings.Builder
+			if err := program.RunRecords(strings.NewReader(tt.input), &out, RecordSplit{}); err != nil {
+				t.Fatalf("RunRecords() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("RunRecords() wrote %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+
+	// The encoding found in the first chunk holds for the later ones
+	program, err := Options{}.Compile(`end-of-line; insert "ü"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	in := &lineReader{lines: []string{"abc\n", "caf\xe9\n"}, out: &out}
+	if err := program.RunRecords(in, &out, RecordSplit{}); err != nil {
+		t.Fatalf("RunRecords() error = %v", err)
+	}
+	if want := "abcü\ncaf\xe9ü\n"; out.String() != want {
+		t.Errorf("RunRecords() wrote %q, want %q", out.String(), want)
+	}
+
+	program, err = Options{Encoding: edlisp.EncodingUTF16LE}.Compile(`insert "x"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := program.RunRecords(strings.NewReader("a\x00\n\x00"), io.Discard, RecordSplit{}); err == nil {
+		t.Error("RunRecords() with --encoding utf-16le should fail")
+	}
+	program, err = Options{}.Compile(`insert "x"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := program.RunRecords(strings.NewReader("\xff\xfea\x00\n\x00"), io.Discard, RecordSplit{}); err == nil {
+		t.Error("RunRecords() of UTF-16 input with a byte order mark should fail")
+	}
+}
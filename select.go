@@ -0,0 +1,426 @@
+package texted
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// IgnoreFileNames are the files listing what a recursive FileSelection skips,
+// in the syntax of .gitignore.
+var IgnoreFileNames = []string{".gitignore", ".textedignore"}
+
+// FileSelection chooses the files an edit applies to.
+//
+// Globs are matched against slash-separated paths. A * matches any
+// characters but /, ? matches one character, [...] matches a character
+// class and ** matches any number of path segments. A glob without a slash
+// matches the last element of a path at any depth, so *.go selects Go files
+// everywhere; other globs match the whole path relative to the directory
+// being walked, such as vendor/** or cmd/*/main.go.
+type FileSelection struct {
+	// Recursive makes directories among the paths select the files below them.
+	// Without it, directories are an error.
+	Recursive bool
+
+	// Include lists globs of the files to select. If it is empty, all files are selected.
+	Include []string
+
+	// Exclude lists globs of the files to leave out. Directories matching
+	// one of them are not walked.
+	Exclude []string
+
+	// NoIgnore disables the ignore files. Otherwise directories are walked
+	// like git would: the .git directory and everything matched by a
+	// .gitignore or .textedignore file in the walked directories, or in
+	// their parents up to the root of the git repository, is skipped.
+	NoIgnore bool
+}
+
+// Select returns the files selected from paths, in order and without duplicates.
+// Include and Exclude apply to all files, while ignore files only apply to
+// the files found by walking directories. Paths that cannot be examined are
+// passed through, so that editing them reports the problem.
+func (s FileSelection) Select(paths []string) ([]string, error) {
+	for _, glob := range slices.Concat(s.Include, s.Exclude) {
+		if err := checkGlob(glob); err != nil {
+			return nil, err
+		}
+	}
+
+	var files []string
+	var seen fileIndex
+	add := func(filename string) {
+		if _, ok := seen.lookup(filename, len(files)); !ok {
+			files = append(files, filename)
+		}
+	}
+
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil || !info.IsDir() {
+			if s.selects(filepath.ToSlash(filepath.Clean(p))) {
+				add(p)
+			}
+			continue
+		}
+		if !s.Recursive {
+			return nil, fmt.Errorf("%s is a directory (use recursive selection to edit the files below it)", p)
+		}
+		if err := s.walk(filepath.Clean(p), add); err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+// UniqueFiles returns files without the names that refer to the same file
+// as an earlier one, such as a.txt, ./a.txt, its absolute path or a symbolic
+// link to it. Editing a file twice at the same time would lose one of the edits.
+func UniqueFiles(files []string) []string {
+	unique, _ := uniqueFiles(files)
+	return unique
+}
+
+// uniqueFiles is like UniqueFiles, but also returns for every file the
+// index of the file in the result that it is the same as.
+func uniqueFiles(files []string) ([]string, []int) {
+	unique := make([]string, 0, len(files))
+	index := make([]int, len(files))
+	var seen fileIndex
+	for i, filename := range files {
+		j, ok := seen.lookup(filename, len(unique))
+		if !ok {
+			unique = append(unique, filename)
+		}
+		index[i] = j
+	}
+	return unique, index
+}
+
+// fileIndex numbers files, telling names that refer to the same file apart
+// from different files with os.SameFile. Names of files that cannot be
+// examined, such as missing ones, are the same if they are once cleaned.
+type fileIndex struct {
+	// files holds the files seen so far by size and modification time,
+	// which the same file has under every name.
+	files map[fileKey][]indexedFile
+	names map[string]int
+}
+
+type fileKey struct {
+	size    int64
+	modTime int64
+}
+
+type indexedFile struct {
+	info  fs.FileInfo
+	index int
+}
+
+// lookup returns the number of the file filename refers to and true if it
+// was looked up before. Otherwise the file gets the number next.
+func (x *fileIndex) lookup(filename string, next int) (int, bool) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		if x.names == nil {
+			x.names = make(map[string]int)
+		}
+		if index, ok := x.names[filepath.Clean(filename)]; ok {
+			return index, true
+		}
+		x.names[filepath.Clean(filename)] = next
+		return next, false
+	}
+
+	if x.files == nil {
+		x.files = make(map[fileKey][]indexedFile)
+	}
+	key := fileKey{info.Size(), info.ModTime().UnixNano()}
+	for _, file := range x.files[key] {
+		if os.SameFile(file.info, info) {
+			return file.index, true
+		}
+	}
+	x.files[key] = append(x.files[key], indexedFile{info, next})
+	return next, false
+}
+
+// selects reports whether the file at the slash-separated path name passes Include and Exclude.
+func (s FileSelection) selects(name string) bool {
+	if s.excludes(name) {
+		return false
+	}
+	if len(s.Include) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(s.Include, func(glob string) bool {
+		return matchGlob(glob, name)
+	})
+}
+
+// excludes reports whether the file or directory at the slash-separated path name is excluded.
+func (s FileSelection) excludes(name string) bool {
+	return slices.ContainsFunc(s.Exclude, func(glob string) bool {
+		return matchGlob(glob, name)
+	})
+}
+
+// walk adds the selected files below root in lexical order.
+func (s FileSelection) walk(root string, add func(string)) error {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	// ignores holds the ignore files that apply inside each walked directory
+	ignores := make(map[string][]*ignoreFile)
+	var parentIgnores []*ignoreFile
+	if !s.NoIgnore {
+		parentIgnores = repositoryIgnoreFiles(absRoot)
+	}
+
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		abs := filepath.Join(absRoot, rel)
+		name := filepath.ToSlash(rel)
+
+		inherited := parentIgnores
+		if p != root {
+			inherited = ignores[filepath.Dir(p)]
+		}
+
+		if d.IsDir() {
+			if p != root && (d.Name() == ".git" || s.excludes(name) || isIgnored(inherited, abs, true)) {
+				return filepath.SkipDir
+			}
+			own := inherited
+			if !s.NoIgnore {
+				own, err = appendIgnoreFiles(slices.Clip(inherited), p, abs)
+				if err != nil {
+					return err
+				}
+			}
+			ignores[p] = own
+			return nil
+		}
+
+		if isIgnored(inherited, abs, false) || !s.selects(name) || !isRegularFile(p, d) {
+			return nil
+		}
+		add(p)
+		return nil
+	})
+}
+
+// isRegularFile reports whether the walked entry d is a regular file or a
+// symbolic link to one.
+func isRegularFile(p string, d fs.DirEntry) bool {
+	if d.Type().IsRegular() {
+		return true
+	}
+	if d.Type()&fs.ModeSymlink == 0 {
+		return false
+	}
+	info, err := os.Stat(p)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// checkGlob reports an error if glob is malformed.
+func checkGlob(glob string) error {
+	for _, segment := range strings.Split(glob, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid glob %q: %w", glob, err)
+		}
+	}
+	return nil
+}
+
+// matchGlob reports whether the slash-separated relative path name matches glob.
+func matchGlob(glob, name string) bool {
+	glob = strings.TrimSuffix(glob, "/")
+	if !strings.Contains(glob, "/") {
+		ok, _ := path.Match(glob, path.Base(name))
+		return ok
+	}
+	return matchSegments(strings.Split(strings.TrimPrefix(glob, "/"), "/"), strings.Split(name, "/"))
+}
+
+// matchSegments matches path segments against glob segments, where ** matches
+// any number of segments.
+func matchSegments(glob, name []string) bool {
+	for len(glob) > 0 {
+		if glob[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(glob[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(glob[0], name[0]); !ok {
+			return false
+		}
+		glob, name = glob[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// ignoreFile holds the rules of a .gitignore or .textedignore file.
+type ignoreFile struct {
+	// dir is the absolute path of the directory containing the file,
+	// which anchored rules are relative to.
+	dir   string
+	rules []ignoreRule
+}
+
+// ignoreRule is one pattern of an ignore file.
+type ignoreRule struct {
+	segments []string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// isIgnored reports whether the file or directory at the absolute path abs is
+// ignored. The last matching rule decides, so later files override earlier ones.
+func isIgnored(files []*ignoreFile, abs string, isDir bool) bool {
+	ignored := false
+	for _, file := range files {
+		rel, err := filepath.Rel(file.dir, abs)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		for _, rule := range file.rules {
+			if rule.dirOnly && !isDir {
+				continue
+			}
+			if rule.matches(rel) {
+				ignored = !rule.negate
+			}
+		}
+	}
+	return ignored
+}
+
+// matches reports whether the rule matches the path rel relative to its ignore file.
+// Rules without a slash match a name at any depth, like in .gitignore.
+func (r ignoreRule) matches(rel string) bool {
+	if !r.anchored {
+		ok, _ := path.Match(r.segments[0], path.Base(rel))
+		return ok
+	}
+	return matchSegments(r.segments, strings.Split(rel, "/"))
+}
+
+// appendIgnoreFiles appends the ignore files found in the directory p, whose
+// absolute path is abs, to files.
+func appendIgnoreFiles(files []*ignoreFile, p, abs string) ([]*ignoreFile, error) {
+	for _, name := range IgnoreFileNames {
+		file, err := readIgnoreFile(filepath.Join(p, name), abs)
+		if err != nil {
+			return nil, err
+		}
+		if file != nil {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// repositoryIgnoreFiles returns the ignore files in the parent directories of
+// dir up to the root of the git repository containing it, outermost first.
+// Outside a git repository there are none.
+func repositoryIgnoreFiles(dir string) []*ignoreFile {
+	var parents []string
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return nil
+		}
+		parents = append(parents, parent)
+		current = parent
+	}
+
+	var files []*ignoreFile
+	for _, parent := range slices.Backward(parents) {
+		// Unreadable ignore files outside the walked directory are not worth failing for
+		files, _ = appendIgnoreFiles(files, parent, parent)
+	}
+	return files
+}
+
+// readIgnoreFile parses the ignore file at filename, which applies to the
+// directory dir. It returns nil if there is no such file.
+func readIgnoreFile(filename, dir string) (*ignoreFile, error) {
+	f, err := os.Open(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	file := &ignoreFile{dir: dir}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreRule(scanner.Text()); ok {
+			file.rules = append(file.rules, rule)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", filename, err)
+	}
+	return file, nil
+}
+
+// parseIgnoreRule parses a line of an ignore file.
+// It returns false for blank lines and comments.
+func parseIgnoreRule(line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+	switch {
+	case strings.HasPrefix(line, "!"):
+		rule.negate = true
+		line = line[1:]
+	case strings.HasPrefix(line, `\#`), strings.HasPrefix(line, `\!`):
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	rule.anchored = strings.Contains(line, "/")
+	rule.segments = strings.Split(strings.TrimPrefix(line, "/"), "/")
+	return rule, true
+}
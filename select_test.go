@@ -0,0 +1,136 @@
+package texted
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFileSelection(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		".git/config":             "",
+		".gitignore":              "*.gen.go\nbuild/\n!keep.gen.go\n",
+		"main.go":                 "",
+		"main.gen.go":             "",
+		"keep.gen.go":             "",
+		"README.md":               "",
+		"build/out.go":            "",
+		"vendor/lib/lib.go":       "",
+		"cmd/tool/main.go":        "",
+		"cmd/tool/.textedignore":  "/local.go\n",
+		"cmd/tool/local.go":       "",
+		"cmd/tool/sub/local.go":   "",
+		"cmd/tool/sub/notes.txt":  "",
+		"cmd/tool/sub/.gitignore": "notes.txt\n",
+	} {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rel := func(names ...string) []string {
+		var paths []string
+		for _, name := range names {
+			paths = append(paths, filepath.Join(dir, filepath.FromSlash(name)))
+		}
+		return paths
+	}
+
+	tests := []struct {
+		name      string
+		selection FileSelection
+		paths     []string
+		want      []string
+	}{
+		{
+			name:      "recursive honours ignore files",
+			selection: FileSelection{Recursive: true, Include: []string{"*.go"}, Exclude: []string{"vendor/**"}},
+			paths:     rel("."),
+			want:      rel("cmd/tool/main.go", "cmd/tool/sub/local.go", "keep.gen.go", "main.go"),
+		},
+		{
+			name:      "ignore files of parent directories apply",
+			selection: FileSelection{Recursive: true},
+			paths:     rel("cmd/tool/sub"),
+			want:      rel("cmd/tool/sub/.gitignore", "cmd/tool/sub/local.go"),
+		},
+		{
+			name:      "no ignore",
+			selection: FileSelection{Recursive: true, Include: []string{"*.go"}, NoIgnore: true},
+			paths:     rel("build", "main.gen.go"),
+			want:      rel("build/out.go", "main.gen.go"),
+		},
+		{
+			name:      "explicit files are filtered but not ignored",
+			selection: FileSelection{Include: []string{"*.go"}},
+			paths:     rel("main.gen.go", "README.md", "main.go", "main.gen.go"),
+			want:      rel("main.gen.go", "main.go"),
+		},
+		{
+			name:      "globs with slashes match below the walked directory",
+			selection: FileSelection{Recursive: true, Include: []string{"cmd/**/main.go"}},
+			paths:     rel("."),
+			want:      rel("cmd/tool/main.go"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.selection.Select(tt.paths)
+			if err != nil {
+				t.Fatalf("Select() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Select() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (FileSelection{}).Select(rel("cmd")); err == nil {
+		t.Error("Select() of a directory without Recursive should fail")
+	}
+	if _, err := (FileSelection{Include: []string{"[a"}}).Select(nil); err == nil {
+		t.Error("Select() with an invalid glob should fail")
+	}
+}
+
+func TestUniqueFiles(t *testing.T) {
+	got := UniqueFiles([]string{"a.txt", "./a.txt", "b.txt", "dir/../a.txt", "b.txt"})
+	if want := []string{"a.txt", "b.txt"}; !slices.Equal(got, want) {
+		t.Errorf("UniqueFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestUniqueFiles_SameFile(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "b.txt")
+	for _, filename := range []string{a, b} {
+		if err := os.WriteFile(filename, []byte("text\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link := filepath.Join(dir, "link.txt")
+	if err := os.Symlink("a.txt", link); err != nil {
+		t.Skip("symbolic links not supported:", err)
+	}
+	t.Chdir(dir)
+
+	got := UniqueFiles([]string{"a.txt", a, "b.txt", link, "./link.txt", "missing.txt", "./missing.txt"})
+	if want := []string{"a.txt", "b.txt", "missing.txt"}; !slices.Equal(got, want) {
+		t.Errorf("UniqueFiles() = %v, want %v", got, want)
+	}
+
+	files, err := FileSelection{}.Select([]string{link, "a.txt", b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{link, b}; !slices.Equal(files, want) {
+		t.Errorf("Select() = %v, want %v", files, want)
+	}
+}
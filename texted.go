@@ -1,8 +1,10 @@
 package texted
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"sort"
+	"time"
 
 	"github.com/dhamidi/texted/edlisp"
 	"github.com/dhamidi/texted/edlisp/parser"
@@ -13,34 +15,199 @@ type EditResult struct {
 	Filename string
 	Success  bool
 	Error    error
+
+	// Matches counts the matches the script ran at if Options.Each is set.
+	Matches *edlisp.EachResult
+
+	// Skipped is ErrBinary or ErrGenerated if the file was left alone
+	// because of its content, as decided by Options.CheckContent.
+	// Success is false and Error is nil then.
+	Skipped error
+}
+
+// Options configures how texted scripts are executed.
+// The zero value executes shell-format scripts without file access
+// and without running external commands.
+type Options struct {
+	// Format is the script format: shell, sexp or json. Empty means shell.
+	Format string
+
+	// FileSystem gives scripts access to files through builtins such as
+	// insert-file-contents and write-region. Nil disables file access.
+	FileSystem edlisp.FileSystem
+
+	// AllowedCommands lists the programs scripts may run through
+	// shell-command-on-region and shell-command-to-string.
+	AllowedCommands []string
+
+	// CommandTimeout limits how long such a program may run.
+	// Zero means edlisp.DefaultCommandTimeout.
+	CommandTimeout time.Duration
+
+	// Output receives the text scripts print with message and princ.
+	// Nil discards it; use an *edlisp.CapturedOutput to collect it.
+	Output edlisp.OutputSink
+
+	// Plugins are external programs providing additional builtins,
+	// usually found with edlisp.DiscoverPlugins. Plugins whose name is
+	// already taken are skipped.
+	Plugins []*edlisp.Plugin
+
+	// LoadPath lists the directories load searches for script files.
+	// An empty LoadPath disables load.
+	LoadPath []string
+
+	// Args holds the values of the script arguments, which scripts refer to
+	// as $NAME or (arg "NAME"). Missing arguments are reported before the
+	// script is evaluated.
+	Args map[string]string
+
+	// Jobs is the number of files EditFilesWithOptions edits at the same
+	// time. Zero means runtime.GOMAXPROCS(0).
+	Jobs int
+
+	// Each is a regular expression. If set, the script runs once at every
+	// match instead of once for the whole input, as described for
+	// edlisp.EvalEach. Failing at a match undoes the edits made at that
+	// match only; use ExecuteScriptEach to learn how many matches failed.
+	Each string
+
+	// EOL converts the line endings of the edited text to the given
+	// convention. Empty keeps the line endings the text has.
+	EOL edlisp.EOLType
+
+	// Encoding is the character encoding to read the edited text with.
+	// Empty detects it as described for edlisp.DetectEncoding. The text is
+	// written back in the same encoding unless the script changes it.
+	Encoding edlisp.Encoding
+
+	// Write configures how edited files are written back.
+	Write WriteOptions
+
+	// Binary makes the edit functions edit files that look binary instead
+	// of skipping them. See IsBinary.
+	Binary bool
+
+	// AllowGenerated makes the edit functions edit generated files instead
+	// of skipping them. See IsGenerated.
+	AllowGenerated bool
+
+	// Atomic makes EditFilesWithOptions edit all files or none: the script
+	// runs on every file in memory, and files are only written if it
+	// succeeds on all of them. If writing fails, the files already written
+	// are restored.
+	Atomic bool
+
+	// Backup is a suffix. If set, the edit functions copy every file to
+	// its name plus Backup with WriteBackup before writing it.
+	Backup string
+
+	// DryRun makes the edit functions run the script on every file
+	// without writing any file.
+	DryRun bool
+}
+
+// NewEnvironment creates the evaluation environment described by the options.
+// It fails if a plugin cannot be registered, for example because its name
+// is taken by a builtin or another plugin.
+func (o Options) NewEnvironment() (*edlisp.Environment, error) {
+	env := edlisp.NewDefaultEnvironment()
+	env.FileSystem = o.FileSystem
+	env.AllowedCommands = o.AllowedCommands
+	env.CommandTimeout = o.CommandTimeout
+	env.Output = o.Output
+	env.LoadPath = o.LoadPath
+	env.ParseScript = parser.ParseScript
+	env.Args = o.Args
+	for _, plugin := range o.Plugins {
+		if err := env.RegisterPlugin(plugin); err != nil {
+			return nil, fmt.Errorf("registering plugin %s: %w", plugin.Path, err)
+		}
+	}
+	return env, nil
+}
+
+// NewBuffer creates a buffer holding input converted to the buffer
+// conventions, as described for edlisp.DecodeTextAs with o.Encoding. The
+// buffer remembers how input was stored, with the line endings replaced by
+// o.EOL if it is set, so that the edited text can be converted back with
+// Coding().Encode. With o.EOL, every line ending of input is converted, even
+// if input mixes several kinds.
+func (o Options) NewBuffer(input string) *edlisp.Buffer {
+	text, coding := edlisp.DecodeTextAs(input, o.Encoding)
+	if o.EOL != "" {
+		text = edlisp.NormalizeLineEndings(text)
+		coding.EOL = o.EOL
+	}
+	buf := edlisp.NewBuffer(text)
+	buf.SetCoding(coding)
+	return buf
+}
+
+func (o Options) format() string {
+	if o.Format == "" {
+		return "shell"
+	}
+	return o.Format
 }
 
 // ExecuteScript executes a texted script on the given input and returns the result.
 func ExecuteScript(input, script string) (string, error) {
+	return ExecuteScriptWithOptions(input, script, Options{})
+}
+
+// ExecuteScriptWithBuffers executes a texted script on the given input with additional named buffers.
+// The buffers map preloads buffers by name, so the script can switch to them with set-buffer
+// or copy from them with insert-buffer-substring.
+// It returns the modified input together with the contents of all buffers after evaluation,
+// keyed by name. The input itself is available as edlisp.MainBufferName.
+func ExecuteScriptWithBuffers(input, script string, buffers map[string]string) (string, map[string]string, error) {
 	buf := edlisp.NewBuffer(input)
+	state := edlisp.NewState(buf)
+
+	names := make([]string, 0, len(buffers))
+	for name := range buffers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := state.AddBuffer(name, edlisp.NewBuffer(buffers[name])); err != nil {
+			return "", nil, err
+		}
+	}
 
 	program, err := parser.ParseString(script)
 	if err != nil {
-		return "", fmt.Errorf("parsing script: %w", err)
+		return "", nil, fmt.Errorf("parsing script: %w", err)
 	}
 
 	env := edlisp.NewDefaultEnvironment()
 	_, err = edlisp.Eval(program, env, buf)
 	if err != nil {
-		return "", fmt.Errorf("script execution failed: %w", err)
+		return "", nil, fmt.Errorf("script execution failed: %w", err)
+	}
+
+	result := make(map[string]string, len(state.Buffers()))
+	for _, b := range state.Buffers() {
+		result[b.Name()] = b.String()
 	}
 
-	return buf.String(), nil
+	return buf.String(), result, nil
 }
 
 // ExecuteScriptWithFormat executes a texted script with a specific format on the given input.
 func ExecuteScriptWithFormat(input, script, format string) (string, error) {
+	return ExecuteScriptWithOptions(input, script, Options{Format: format})
+}
+
+// Parse parses script in the configured format and binds its arguments.
+// It fails if the script is invalid or uses arguments missing from Args.
+func (o Options) Parse(script string) ([]edlisp.Value, error) {
+	format := o.format()
 	if !IsValidFormat(format) {
-		return "", fmt.Errorf("invalid script format: %s (must be shell, sexp, or json)", format)
+		return nil, fmt.Errorf("invalid script format: %s (must be shell, sexp, or json)", format)
 	}
 
-	buf := edlisp.NewBuffer(input)
-
 	var program []edlisp.Value
 	var err error
 
@@ -50,92 +217,148 @@ func ExecuteScriptWithFormat(input, script, format string) (string, error) {
 	case "json":
 		program, err = parser.ParseJSONString(script)
 	default:
-		return "", fmt.Errorf("unsupported script format: %s", format)
+		return nil, fmt.Errorf("unsupported script format: %s", format)
 	}
 
 	if err != nil {
-		return "", fmt.Errorf("parsing script: %w", err)
+		return nil, fmt.Errorf("parsing script: %w", err)
 	}
 
-	env := edlisp.NewDefaultEnvironment()
-	_, err = edlisp.Eval(program, env, buf)
+	return edlisp.BindArguments(program, o.Args)
+}
+
+// ExecuteScriptWithOptions executes a texted script on the given input as configured by opts.
+func ExecuteScriptWithOptions(input, script string, opts Options) (string, error) {
+	program, err := opts.Compile(script)
 	if err != nil {
-		return "", fmt.Errorf("script execution failed: %w", err)
+		return "", err
 	}
 
-	return buf.String(), nil
+	output, _, err := program.Run(input)
+	return output, err
 }
 
-// EditFile applies a texted script to a file.
-func EditFile(filename, script string) error {
-	content, err := readFile(filename)
+// ExecuteScriptEach executes a texted script at every match of opts.Each in input.
+// It returns the modified input together with the number of matches that
+// were processed, skipped or failed. Failing at a match is not an
+// error of ExecuteScriptEach; the failures are listed in the result.
+func ExecuteScriptEach(input, script string, opts Options) (string, edlisp.EachResult, error) {
+	if opts.Each == "" {
+		return "", edlisp.EachResult{}, fmt.Errorf("each regexp is required")
+	}
+	program, err := opts.Compile(script)
 	if err != nil {
-		return err
+		return "", edlisp.EachResult{}, err
 	}
 
-	modified, err := ExecuteScript(content, script)
+	output, result, err := program.Run(input)
 	if err != nil {
-		return err
+		return "", edlisp.EachResult{}, err
 	}
+	return output, *result, nil
+}
 
-	return writeFile(filename, modified)
+// EditFile applies a texted script to a file.
+func EditFile(filename, script string) error {
+	return EditFileWithOptions(filename, script, Options{})
 }
 
 // EditFileWithFormat applies a texted script with a specific format to a file.
 func EditFileWithFormat(filename, script, format string) error {
-	content, err := readFile(filename)
-	if err != nil {
-		return err
-	}
+	return EditFileWithOptions(filename, script, Options{Format: format})
+}
 
-	modified, err := ExecuteScriptWithFormat(content, script, format)
+// EditFileWithOptions applies a texted script to a file as configured by opts.
+// Binary and generated files are not edited unless opts allow it; the error
+// then wraps ErrBinary or ErrGenerated.
+func EditFileWithOptions(filename, script string, opts Options) error {
+	program, err := opts.Compile(script)
 	if err != nil {
 		return err
 	}
 
-	return writeFile(filename, modified)
+	opts.Atomic = false
+	var result EditResult
+	opts.EditFiles(program, []string{filename}, func(file *FileResult) {
+		result = opts.editResult(file)
+	})
+	if result.Skipped != nil {
+		return fmt.Errorf("skipping %s: %w", filename, result.Skipped)
+	}
+	return result.Error
 }
 
 // EditFiles applies a texted script to multiple files.
 func EditFiles(files []string, script string) ([]EditResult, error) {
-	results := make([]EditResult, 0, len(files))
-
-	for _, filename := range files {
-		result := EditResult{Filename: filename}
+	return EditFilesWithOptions(files, script, Options{})
+}
 
-		err := EditFile(filename, script)
-		if err != nil {
-			result.Success = false
-			result.Error = err
-		} else {
-			result.Success = true
-		}
+// EditFilesWithFormat applies a texted script with a specific format to multiple files.
+func EditFilesWithFormat(files []string, script, format string) ([]EditResult, error) {
+	return EditFilesWithOptions(files, script, Options{Format: format})
+}
 
-		results = append(results, result)
+// EditFilesWithOptions applies a texted script to multiple files as configured by opts.
+// The script is parsed once and checked before any file is touched; if it is
+// invalid or uses missing arguments, no file is edited and the error is returned.
+// Up to opts.Jobs files are edited at the same time, but the results and the
+// text the script prints come in the order of files.
+// With opts.Atomic, a failure on one file leaves all files unedited and the
+// other files report ErrAborted. Binary and generated files are skipped
+// unless opts allow them, which is reported in EditResult.Skipped.
+// A file listed more than once is edited once, and its later entries repeat
+// the result of the first.
+func EditFilesWithOptions(files []string, script string, opts Options) ([]EditResult, error) {
+	program, err := opts.Compile(script)
+	if err != nil {
+		return nil, err
 	}
 
+	unique, index := uniqueFiles(files)
+	uniqueResults := make([]EditResult, 0, len(unique))
+	opts.EditFiles(program, unique, func(file *FileResult) {
+		uniqueResults = append(uniqueResults, opts.editResult(file))
+	})
+
+	results := make([]EditResult, len(files))
+	for i, j := range index {
+		results[i] = uniqueResults[j]
+		results[i].Filename = files[i]
+	}
 	return results, nil
 }
 
-// EditFilesWithFormat applies a texted script with a specific format to multiple files.
-func EditFilesWithFormat(files []string, script, format string) ([]EditResult, error) {
-	results := make([]EditResult, 0, len(files))
-
-	for _, filename := range files {
-		result := EditResult{Filename: filename}
-
-		err := EditFileWithFormat(filename, script, format)
-		if err != nil {
-			result.Success = false
-			result.Error = err
-		} else {
-			result.Success = true
+// editResult passes on what the script printed for file to o.Output and
+// returns the EditResult describing file.
+func (o Options) editResult(file *FileResult) EditResult {
+	if o.Output != nil {
+		for _, text := range file.Output.Entries {
+			o.Output.Output(text)
 		}
-
-		results = append(results, result)
 	}
 
-	return results, nil
+	result := EditResult{
+		Filename: file.Filename,
+		Success:  file.Err == nil && file.Skipped == nil,
+		Skipped:  file.Skipped,
+	}
+	if file.Run != nil {
+		result.Matches = file.Run.Matches
+	}
+	switch file.Stage {
+	case StageRead:
+		result.Error = fmt.Errorf("failed to read file %s: %w", file.Filename, file.Err)
+	case StageBackup:
+		result.Error = fmt.Errorf("failed to back up file %s: %w", file.Filename, file.Err)
+	case StageWrite:
+		result.Error = file.Err
+		if !errors.As(file.Err, new(*CommitError)) {
+			result.Error = fmt.Errorf("failed to write file %s: %w", file.Filename, file.Err)
+		}
+	default:
+		result.Error = file.Err
+	}
+	return result
 }
 
 // IsValidFormat checks if a format string is valid.
@@ -147,21 +370,3 @@ func IsValidFormat(format string) bool {
 		return false
 	}
 }
-
-// readFile reads the content of a file.
-func readFile(filename string) (string, error) {
-	content, err := os.ReadFile(filename)
-	if err != nil {
-		return "", fmt.Errorf("failed to read file %s: %w", filename, err)
-	}
-	return string(content), nil
-}
-
-// writeFile writes content to a file.
-func writeFile(filename, content string) error {
-	err := os.WriteFile(filename, []byte(content), 0644)
-	if err != nil {
-		return fmt.Errorf("failed to write file %s: %w", filename, err)
-	}
-	return nil
-}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/dhamidi/texted"
+	"github.com/dhamidi/texted/edlisp"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -25,30 +26,81 @@ func NewEditFileToolWithPrefix(prefix string) mcp.Tool {
 			mcp.Description("The texted script to execute on each file"),
 		),
 		mcp.WithArray("files",
-			mcp.Required(),
 			mcp.Description("List of file paths to edit"),
 		),
+		mcp.WithArray("globs",
+			mcp.Description("Glob patterns selecting files to edit below the working directory, such as \"**/*.go\" or \"cmd/*/main.go\"; files ignored by .gitignore and .textedignore are skipped"),
+		),
+		mcp.WithArray("exclude",
+			mcp.Description("Glob patterns of files and directories to leave out of the files selected by globs, such as \"vendor/**\""),
+		),
 		mcp.WithBoolean("loopUntilError",
 			mcp.Description("Run the script repeatedly until an error is returned"),
 		),
+		mcp.WithString("each",
+			mcp.Description("Regular expression. If given, the script runs once at every match, with point at the end of the match, mark at its start and the match data set for replace-match"),
+		),
+		mcp.WithBoolean("atomic",
+			mcp.Description("Edit all files or none: write nothing unless the script succeeds on every file"),
+		),
+		mcp.WithBoolean("binary",
+			mcp.Description("Edit files that look binary instead of skipping them"),
+		),
+		mcp.WithBoolean("allowGenerated",
+			mcp.Description("Edit generated files, marked by headers such as \"DO NOT EDIT\", instead of skipping them"),
+		),
+		mcp.WithObject("args",
+			mcp.Description("Values of the script arguments, which the script refers to as $NAME or (arg \"NAME\")"),
+			mcp.AdditionalProperties(map[string]any{"type": "string"}),
+		),
 	)
 }
 
+// EditFileHandler handles edit_file calls with default options.
 func EditFileHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return Options{}.EditFileHandler(ctx, request)
+}
+
+// EditFileHandler handles edit_file calls as configured by o.
+func (o Options) EditFileHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	script, err := request.RequireString("script")
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("script parameter required: %v", err)), nil
 	}
 
-	files, err := request.RequireStringSlice("files")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("files parameter required: %v", err)), nil
+	files := request.GetStringSlice("files", nil)
+	if globs := request.GetStringSlice("globs", nil); len(globs) > 0 {
+		selected, err := texted.FileSelection{
+			Recursive: true,
+			Include:   globs,
+			Exclude:   request.GetStringSlice("exclude", nil),
+		}.Select([]string{"."})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("selecting files: %v", err)), nil
+		}
+		files = append(files, selected...)
 	}
 
 	loopUntilError := request.GetBool("loopUntilError", false)
 
+	args, err := scriptArguments(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	if len(files) == 0 {
-		return mcp.NewToolResultError("at least one file must be specified"), nil
+		return mcp.NewToolResultError("at least one file must be specified with files or globs"), nil
+	}
+
+	var messages edlisp.CapturedOutput
+	opts := o.scriptOptions("shell", &messages)
+	opts.Args = args
+	opts.Each = request.GetString("each", "")
+	opts.Atomic = request.GetBool("atomic", false)
+	opts.Binary = request.GetBool("binary", false)
+	opts.AllowGenerated = request.GetBool("allowGenerated", false)
+	if opts.Each != "" && loopUntilError {
+		return mcp.NewToolResultError("each cannot be used with loopUntilError"), nil
 	}
 
 	var editResults []texted.EditResult
@@ -56,21 +108,29 @@ func EditFileHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.Cal
 	var iterations int
 
 	if loopUntilError {
-		editResults, iterations, editErr = editFilesWithLoop(files, script)
+		editResults, iterations, editErr = editFilesWithLoop(files, script, opts)
 	} else {
-		editResults, editErr = texted.EditFiles(files, script)
+		editResults, editErr = texted.EditFilesWithOptions(files, script, opts)
 		iterations = 1
 	}
 
 	if editErr != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to edit files: %v", editErr)), nil
+		return withOutput(mcp.NewToolResultError(fmt.Sprintf("Failed to edit files: %v", editErr)), &messages), nil
 	}
 
 	var results []string
+	var skipped []string
 	var errors []string
 
 	for _, result := range editResults {
-		if result.Success {
+		if result.Skipped != nil {
+			skipped = append(skipped, fmt.Sprintf("Skipped %s: %v", result.Filename, result.Skipped))
+		} else if result.Success && result.Matches != nil {
+			results = append(results, fmt.Sprintf("Successfully edited %s (%s)", result.Filename, result.Matches))
+			for _, matchErr := range result.Matches.Errors {
+				errors = append(errors, fmt.Sprintf("%s: %v", result.Filename, matchErr))
+			}
+		} else if result.Success {
 			results = append(results, fmt.Sprintf("Successfully edited %s", result.Filename))
 		} else {
 			errors = append(errors, fmt.Sprintf("Failed to edit %s: %v", result.Filename, result.Error))
@@ -82,42 +142,51 @@ func EditFileHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.Cal
 		for _, result := range results {
 			message += fmt.Sprintf("✓ %s\n", result)
 		}
+		for _, skip := range skipped {
+			message += fmt.Sprintf("- %s\n", skip)
+		}
 		for _, errMsg := range errors {
 			message += fmt.Sprintf("✗ %s\n", errMsg)
 		}
-		return mcp.NewToolResultText(message), nil
+		return withOutput(mcp.NewToolResultText(message), &messages), nil
 	}
 
 	message := fmt.Sprintf("All files edited successfully after %d iterations:\n", iterations)
 	for _, result := range results {
 		message += fmt.Sprintf("✓ %s\n", result)
 	}
+	for _, skip := range skipped {
+		message += fmt.Sprintf("- %s\n", skip)
+	}
 
-	return mcp.NewToolResultText(message), nil
+	return withOutput(mcp.NewToolResultText(message), &messages), nil
 }
 
 // editFilesWithLoop repeatedly applies a script to files until an error occurs
-func editFilesWithLoop(files []string, script string) ([]texted.EditResult, int, error) {
+func editFilesWithLoop(files []string, script string, opts texted.Options) ([]texted.EditResult, int, error) {
 	iterations := 0
 	var lastResults []texted.EditResult
 
 	for {
 		iterations++
-		results, err := texted.EditFiles(files, script)
+		results, err := texted.EditFilesWithOptions(files, script, opts)
 		if err != nil {
 			return lastResults, iterations, err
 		}
 
-		// Check if any file had an error
+		// Check if any file had an error; skipped files never change, so
+		// the loop also ends if there is nothing else to edit
 		hasError := false
+		edited := false
 		for _, result := range results {
-			if !result.Success {
+			if result.Error != nil {
 				hasError = true
 				break
 			}
+			edited = edited || result.Success
 		}
 
-		if hasError {
+		if hasError || !edited {
 			return results, iterations, nil
 		}
 
@@ -150,3 +150,81 @@ func TestEditFileHandler_NonexistentFile(t *testing.T) {
 		t.Errorf("Result should contain error message for nonexistent file, got: %s", textContent.Text)
 	}
 }
+
+func TestEditFileHandler_Atomic(t *testing.T) {
+	tmpDir := t.TempDir()
+	first := filepath.Join(tmpDir, "first.txt")
+	second := filepath.Join(tmpDir, "second.txt")
+	if err := os.WriteFile(first, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte("other"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"script": `search-forward "old"; replace-match "new"`,
+				"files":  []string{first, second},
+				"atomic": true,
+			},
+		},
+	}
+
+	result, err := EditFileHandler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("EditFileHandler() error = %v", err)
+	}
+
+	content, err := os.ReadFile(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "old" {
+		t.Errorf("first file = %q, want it unedited", content)
+	}
+
+	textContent, _ := mcp.AsTextContent(result.Content[0])
+	if !strings.Contains(textContent.Text, "Failed to edit "+first+": "+texted.ErrAborted.Error()) {
+		t.Errorf("Result should report the aborted file, got: %s", textContent.Text)
+	}
+}
+
+func TestEditFileHandler_Globs(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"a.go", "sub/b.go", "sub/c.txt", "vendor/d.go"} {
+		filename := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte("old"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(tmpDir)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"script":  `search-forward "old"; replace-match "new"`,
+				"globs":   []interface{}{"*.go"},
+				"exclude": []interface{}{"vendor/**"},
+			},
+		},
+	}
+
+	if _, err := EditFileHandler(context.Background(), request); err != nil {
+		t.Fatalf("EditFileHandler() error = %v", err)
+	}
+
+	for name, want := range map[string]string{"a.go": "new", "sub/b.go": "new", "sub/c.txt": "old", "vendor/d.go": "old"} {
+		content, err := os.ReadFile(filepath.Join(tmpDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != want {
+			t.Errorf("%s = %q, want %q", name, content, want)
+		}
+	}
+}
@@ -33,7 +33,18 @@ func NewTextedDocToolWithPrefix(prefix string) mcp.Tool {
 	)
 }
 
+// TextedDocHandler handles texted_doc calls with default options.
 func TextedDocHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return Options{}.TextedDocHandler(ctx, request)
+}
+
+// TextedDocHandler handles texted_doc calls as configured by o.
+// It documents the functions available to scripts run with the same options.
+func (o Options) TextedDocHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	env, err := o.scriptOptions("shell", nil).NewEnvironment()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	functionName := request.GetString("function_name", "")
 	category := request.GetString("category", "")
 	verbose := request.GetBool("verbose", false)
@@ -45,23 +56,23 @@ func TextedDocHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 
 	// Handle specific function documentation
 	if functionName != "" {
-		return handleFunctionDoc(functionName)
+		return handleFunctionDoc(env, functionName)
 	}
 
 	// Handle category filtering
 	if category != "" {
-		return handleCategoryListing(category, verbose)
+		return handleCategoryListing(env, category, verbose)
 	}
 
 	// Handle listing all functions
-	return handleAllFunctions(verbose)
+	return handleAllFunctions(env, verbose)
 }
 
-func handleFunctionDoc(functionName string) (*mcp.CallToolResult, error) {
-	doc, exists := edlisp.GetDocumentation(functionName)
+func handleFunctionDoc(env *edlisp.Environment, functionName string) (*mcp.CallToolResult, error) {
+	doc, exists := env.Documentation(functionName)
 	if !exists {
 		// Get similar function names for suggestions
-		allDocs := edlisp.GetAllDocumentation()
+		allDocs := env.AllDocumentation()
 		var suggestions []string
 		for _, d := range allDocs {
 			if strings.Contains(d.Name, functionName) || strings.Contains(functionName, d.Name) {
@@ -83,11 +94,11 @@ func handleFunctionDoc(functionName string) (*mcp.CallToolResult, error) {
 	return mcp.NewToolResultText(output), nil
 }
 
-func handleCategoryListing(category string, verbose bool) (*mcp.CallToolResult, error) {
-	docs := edlisp.GetDocumentationByCategory(category)
+func handleCategoryListing(env *edlisp.Environment, category string, verbose bool) (*mcp.CallToolResult, error) {
+	docs := env.DocumentationByCategory(category)
 	if len(docs) == 0 {
 		// Get available categories for suggestions
-		categories := edlisp.GetCategories()
+		categories := env.Categories()
 		errorMsg := fmt.Sprintf("No functions found in category '%s'", category)
 		if len(categories) > 0 {
 			errorMsg += fmt.Sprintf(". Available categories: %s", strings.Join(categories, ", "))
@@ -99,8 +110,8 @@ func handleCategoryListing(category string, verbose bool) (*mcp.CallToolResult,
 	return mcp.NewToolResultText(output), nil
 }
 
-func handleAllFunctions(verbose bool) (*mcp.CallToolResult, error) {
-	docs := edlisp.GetAllDocumentation()
+func handleAllFunctions(env *edlisp.Environment, verbose bool) (*mcp.CallToolResult, error) {
+	docs := env.AllDocumentation()
 	if len(docs) == 0 {
 		return mcp.NewToolResultError("No functions documented"), nil
 	}
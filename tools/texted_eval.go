@@ -8,7 +8,6 @@ import (
 
 	"github.com/dhamidi/texted"
 	"github.com/dhamidi/texted/edlisp"
-	"github.com/dhamidi/texted/edlisp/parser"
 	"github.com/dhamidi/texted/edlisp/writer"
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -35,10 +34,26 @@ func NewTextedEvalToolWithPrefix(prefix string) mcp.Tool {
 		mcp.WithString("output",
 			mcp.Description("Output type: 'buffer' (default) returns transformed text, 'expression' returns last evaluated expression value"),
 		),
+		mcp.WithString("format",
+			mcp.Description("Format of the value returned in expression mode: 'sexp' (default) or 'json'. JSON renders t, nil and false as true, null and false"),
+		),
+		mcp.WithString("each",
+			mcp.Description("Regular expression. If given, the script runs once in buffer mode at every match, with point at the end of the match, mark at its start and the match data set for replace-match"),
+		),
+		mcp.WithObject("args",
+			mcp.Description("Values of the script arguments, which the script refers to as $NAME or (arg \"NAME\")"),
+			mcp.AdditionalProperties(map[string]any{"type": "string"}),
+		),
 	)
 }
 
+// TextedEvalHandler handles texted_eval calls with default options.
 func TextedEvalHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return Options{}.TextedEvalHandler(ctx, request)
+}
+
+// TextedEvalHandler handles texted_eval calls as configured by o.
+func (o Options) TextedEvalHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	input, err := request.RequireString("input")
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("input parameter required: %v", err)), nil
@@ -54,36 +69,74 @@ func TextedEvalHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 		return mcp.NewToolResultError("output parameter must be 'buffer' or 'expression'"), nil
 	}
 
+	format := request.GetString("format", "sexp")
+	if format != "sexp" && format != "json" {
+		return mcp.NewToolResultError("format parameter must be 'sexp' or 'json'"), nil
+	}
+
+	args, err := scriptArguments(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var messages edlisp.CapturedOutput
+	opts := o.scriptOptions("shell", &messages)
+	opts.Args = args
+	opts.Each = request.GetString("each", "")
+
+	if opts.Each != "" {
+		if outputMode != "buffer" {
+			return mcp.NewToolResultError("each can only be used in buffer output mode"), nil
+		}
+		output, matches, err := texted.ExecuteScriptEach(input, script, opts)
+		if err != nil {
+			return withOutput(mcp.NewToolResultError(fmt.Sprintf("script execution failed: %v", err)), &messages), nil
+		}
+		summary := matches.String()
+		for _, matchErr := range matches.Errors {
+			summary += fmt.Sprintf("\n%v", matchErr)
+		}
+		result := mcp.NewToolResultText(output)
+		result.Content = append(result.Content, mcp.NewTextContent(summary))
+		return withOutput(result, &messages), nil
+	}
+
 	if outputMode == "buffer" {
 		// Use existing ExecuteScript for buffer mode
-		output, err := texted.ExecuteScript(input, script)
+		output, err := texted.ExecuteScriptWithOptions(input, script, opts)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("script execution failed: %v", err)), nil
+			return withOutput(mcp.NewToolResultError(fmt.Sprintf("script execution failed: %v", err)), &messages), nil
 		}
-		return mcp.NewToolResultText(output), nil
+		return withOutput(mcp.NewToolResultText(output), &messages), nil
 	}
 
 	// Expression mode - need to get the return value
-	buf := edlisp.NewBuffer(input)
+	buf := opts.NewBuffer(input)
 
-	program, err := parser.ParseString(script)
+	program, err := opts.Parse(script)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("parsing script: %v", err)), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	env := edlisp.NewDefaultEnvironment()
+	env, err := opts.NewEnvironment()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	result, err := edlisp.Eval(program, env, buf)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("script execution failed: %v", err)), nil
+		return withOutput(mcp.NewToolResultError(fmt.Sprintf("script execution failed: %v", err)), &messages), nil
 	}
 
-	// Format the result value as string using sexp writer
+	// Format the result value as string using the requested writer
 	var output strings.Builder
-	sexpWriter := &writer.SExpWriter{}
-	err = sexpWriter.WriteValue(&output, result)
+	resultWriter, err := writer.NewWriter(writer.Format(format))
+	if err != nil {
+		return withOutput(mcp.NewToolResultError(fmt.Sprintf("failed to format result: %v", err)), &messages), nil
+	}
+	err = resultWriter.WriteValue(&output, result)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to format result: %v", err)), nil
+		return withOutput(mcp.NewToolResultError(fmt.Sprintf("failed to format result: %v", err)), &messages), nil
 	}
 
-	return mcp.NewToolResultText(output.String()), nil
+	return withOutput(mcp.NewToolResultText(strings.TrimSuffix(output.String(), "\n")), &messages), nil
 }
@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestTextedEvalHandler_Messages(t *testing.T) {
+	ctx := context.Background()
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"input":  "version 1.2.3",
+				"script": "search-forward \"version \"\nmessage \"found at %d\" (point)\nupcase \"x\"",
+			},
+		},
+	}
+
+	result, err := TextedEvalHandler(ctx, request)
+	if err != nil {
+		t.Fatalf("TextedEvalHandler() error = %v", err)
+	}
+
+	if len(result.Content) != 2 {
+		t.Fatalf("Expected buffer and messages as two text blocks, got %d blocks", len(result.Content))
+	}
+	buffer, _ := mcp.AsTextContent(result.Content[0])
+	if buffer.Text != "version 1.2.3" {
+		t.Errorf("Buffer block = %q, want %q", buffer.Text, "version 1.2.3")
+	}
+	messages, _ := mcp.AsTextContent(result.Content[1])
+	if messages.Text != "found at 9\n" {
+		t.Errorf("Messages block = %q, want %q", messages.Text, "found at 9\n")
+	}
+}
+
+func TestTextedEvalHandler_Args(t *testing.T) {
+	ctx := context.Background()
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"input":  `call foo("x")`,
+				"script": "search-forward $OLD\nreplace-match $NEW",
+				"args":   map[string]interface{}{"OLD": `foo("x")`, "NEW": "bar"},
+			},
+		},
+	}
+
+	result, err := TextedEvalHandler(ctx, request)
+	if err != nil {
+		t.Fatalf("TextedEvalHandler() error = %v", err)
+	}
+	textContent, _ := mcp.AsTextContent(result.Content[0])
+	if result.IsError || textContent.Text != "call bar" {
+		t.Errorf("Result = %q, want %q", textContent.Text, "call bar")
+	}
+
+	delete(request.Params.Arguments.(map[string]interface{}), "args")
+	result, err = TextedEvalHandler(ctx, request)
+	if err != nil {
+		t.Fatalf("TextedEvalHandler() error = %v", err)
+	}
+	textContent, _ = mcp.AsTextContent(result.Content[0])
+	if !result.IsError || !strings.Contains(textContent.Text, "missing script arguments: NEW, OLD") {
+		t.Errorf("Expected missing arguments to be reported, got %q", textContent.Text)
+	}
+}
+
+func TestTextedEvalHandler_Each(t *testing.T) {
+	ctx := context.Background()
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"input":  "v1 v2 v3",
+				"script": `replace-match "v9"`,
+				"each":   `v[12]`,
+			},
+		},
+	}
+
+	result, err := TextedEvalHandler(ctx, request)
+	if err != nil {
+		t.Fatalf("TextedEvalHandler() error = %v", err)
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("Expected buffer and match counts as two text blocks, got %d blocks", len(result.Content))
+	}
+	buffer, _ := mcp.AsTextContent(result.Content[0])
+	if buffer.Text != "v9 v9 v3" {
+		t.Errorf("Buffer block = %q, want %q", buffer.Text, "v9 v9 v3")
+	}
+	summary, _ := mcp.AsTextContent(result.Content[1])
+	if summary.Text != "2 matches processed, 0 skipped, 0 failed" {
+		t.Errorf("Summary block = %q", summary.Text)
+	}
+}
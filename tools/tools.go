@@ -1 +1,82 @@
 package tools
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dhamidi/texted"
+	"github.com/dhamidi/texted/edlisp"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Options configures the texted MCP tools.
+// The zero value gives scripts no access to anything but their buffers.
+type Options struct {
+	// FileSystem gives scripts access to files through builtins such as
+	// insert-file-contents and write-region. Nil disables file access.
+	FileSystem edlisp.FileSystem
+
+	// AllowedCommands lists the programs scripts may run through
+	// shell-command-on-region and shell-command-to-string.
+	// An empty list disables shell commands.
+	AllowedCommands []string
+
+	// CommandTimeout limits how long such a program may run.
+	// Zero means edlisp.DefaultCommandTimeout.
+	CommandTimeout time.Duration
+
+	// Plugins are external programs providing additional builtins.
+	Plugins []*edlisp.Plugin
+
+	// LoadPath lists the directories load searches for script files.
+	// An empty LoadPath disables load.
+	LoadPath []string
+
+	// Write configures how edit_file writes the files it edits.
+	Write texted.WriteOptions
+}
+
+// scriptOptions returns the options for executing scripts in the given format.
+// The text printed by message and princ goes to output.
+func (o Options) scriptOptions(format string, output edlisp.OutputSink) texted.Options {
+	return texted.Options{
+		Output:          output,
+		Format:          format,
+		FileSystem:      o.FileSystem,
+		AllowedCommands: o.AllowedCommands,
+		CommandTimeout:  o.CommandTimeout,
+		Plugins:         o.Plugins,
+		LoadPath:        o.LoadPath,
+		Write:           o.Write,
+	}
+}
+
+// withOutput appends the text printed by the script to result as an extra text block.
+func withOutput(result *mcp.CallToolResult, messages *edlisp.CapturedOutput) *mcp.CallToolResult {
+	if len(messages.Entries) > 0 {
+		result.Content = append(result.Content, mcp.NewTextContent(messages.String()))
+	}
+	return result
+}
+
+// scriptArguments returns the script arguments passed in the args object of request.
+func scriptArguments(request mcp.CallToolRequest) (map[string]string, error) {
+	raw, exists := request.GetArguments()["args"]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+	object, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("args must be an object mapping argument names to strings")
+	}
+
+	args := make(map[string]string, len(object))
+	for name, value := range object {
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("args.%s must be a string", name)
+		}
+		args[name] = text
+	}
+	return args, nil
+}
@@ -0,0 +1,133 @@
+package texted
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrAborted is the error of files that were not edited because another
+// file of an atomic edit failed.
+var ErrAborted = errors.New("not edited because another file failed")
+
+// FileEdit is the new content of a file that is edited together with others.
+type FileEdit struct {
+	Filename string
+
+	// Original is the content the file had when it was read, which is
+	// written back if the edit is rolled back.
+	Original string
+
+	// Modified is the content to write.
+	Modified string
+}
+
+// CommitError reports the write that made CommitEdits fail.
+type CommitError struct {
+	// Filename is the file that could not be written.
+	Filename string
+	Err      error
+
+	// Unrestored lists the files that had been written already and could
+	// not be restored to their original content.
+	Unrestored []string
+}
+
+func (e *CommitError) Error() string {
+	message := fmt.Sprintf("writing %s: %v", e.Filename, e.Err)
+	if len(e.Unrestored) > 0 {
+		message += fmt.Sprintf(" (could not restore %s)", strings.Join(e.Unrestored, ", "))
+	}
+	return message
+}
+
+func (e *CommitError) Unwrap() error {
+	return e.Err
+}
+
+// CommitEdits writes the modified content of every edit in order.
+// If a write fails, the files written so far get their original content back,
+// so that either all files are edited or, as far as the file system allows,
+// none is. The error is then a *CommitError.
+func CommitEdits(edits []FileEdit, opts WriteOptions) error {
+	for i, edit := range edits {
+		err := WriteFile(edit.Filename, []byte(edit.Modified), opts)
+		if err == nil {
+			continue
+		}
+
+		commitErr := &CommitError{Filename: edit.Filename, Err: err}
+		for j := i - 1; j >= 0; j-- {
+			written := edits[j]
+			if err := WriteFile(written.Filename, []byte(written.Original), opts); err != nil {
+				commitErr.Unrestored = append(commitErr.Unrestored, written.Filename)
+			}
+		}
+		return commitErr
+	}
+	return nil
+}
+
+// editFilesAtomic runs the program on every file in memory and writes the
+// files only if it succeeds on all of them, for Options.EditFiles.
+func (o Options) editFilesAtomic(program *Program, files []string, report func(*FileResult)) {
+	results := make([]*FileResult, len(files))
+	InOrder(len(files), o.jobs(), func(i int) *FileResult {
+		return runFile(program, files[i])
+	}, func(i int, file *FileResult) {
+		results[i] = file
+	})
+
+	failed := slices.ContainsFunc(results, func(file *FileResult) bool { return file.Err != nil })
+
+	// Skipped files are left as they are
+	var edited []*FileResult
+	var edits []FileEdit
+	for _, file := range results {
+		if file.Run != nil {
+			edited = append(edited, file)
+			edits = append(edits, FileEdit{Filename: file.Filename, Original: file.Original, Modified: file.Run.Output})
+		}
+	}
+
+	// Back up every file before any is written
+	if !failed && o.Backup != "" {
+		for _, file := range edited {
+			if err := WriteBackup(file.Filename, o.Backup, o.Write); err != nil {
+				file.Err, file.Stage = err, StageBackup
+				failed = true
+				break
+			}
+			file.BackedUp = true
+		}
+	}
+
+	if !failed {
+		var commitErr *CommitError
+		if err := CommitEdits(edits, o.Write); errors.As(err, &commitErr) {
+			failed = true
+			for _, file := range edited {
+				switch {
+				case file.Filename == commitErr.Filename:
+					file.Err, file.Stage = commitErr, StageWrite
+				case slices.Contains(commitErr.Unrestored, file.Filename):
+					file.Err, file.Stage = fmt.Errorf("edited, but could not be restored after writing %s failed", commitErr.Filename), StageRestore
+					file.Written = true
+				}
+			}
+		}
+	}
+
+	for _, file := range edited {
+		switch {
+		case !failed:
+			file.Written = true
+		case file.Err == nil:
+			file.Err, file.Stage = ErrAborted, StageScript
+		}
+	}
+	for _, file := range results {
+		report(file)
+	}
+}
@@ -0,0 +1,189 @@
+package texted
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+)
+
+// SymlinkPolicy decides what writing a file does when its name is a symbolic link.
+type SymlinkPolicy string
+
+const (
+	// FollowSymlinks writes to the file the link points to and keeps the link.
+	// It is the default.
+	FollowSymlinks SymlinkPolicy = "follow"
+
+	// RefuseSymlinks fails with ErrSymlink instead of writing through a link.
+	RefuseSymlinks SymlinkPolicy = "refuse"
+)
+
+// ErrSymlink is returned when writing a symbolic link is refused.
+var ErrSymlink = errors.New("refusing to write through symbolic link")
+
+// WriteOptions configures how edited files and backups are written.
+// The zero value follows symbolic links and does not sync.
+type WriteOptions struct {
+	// Symlinks is the policy for file names that are symbolic links.
+	// Empty means FollowSymlinks.
+	Symlinks SymlinkPolicy
+
+	// Sync flushes files and their directory to stable storage before a
+	// write returns, so that an edit survives a crash of the machine.
+	Sync bool
+}
+
+// IsValidSymlinkPolicy checks if a symlink policy name is valid.
+func IsValidSymlinkPolicy(policy string) bool {
+	switch SymlinkPolicy(policy) {
+	case FollowSymlinks, RefuseSymlinks:
+		return true
+	default:
+		return false
+	}
+}
+
+// WriteFile replaces the content of filename with data.
+//
+// The data is written to a temporary file in the same directory, which is
+// then renamed over filename, so readers see either the old or the new
+// content and a failed write leaves the file untouched. An existing file keeps
+// its permissions and, where the operating system allows it, its owner and
+// group. New files are created with mode 0644 minus the umask.
+func WriteFile(filename string, data []byte, opts WriteOptions) error {
+	target, info, err := resolveWriteTarget(filename, opts.Symlinks)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(target, data, info, opts.Sync)
+}
+
+// WriteBackup copies filename to filename+suffix the way WriteFile writes,
+// giving the copy the permissions and owner of the original.
+func WriteBackup(filename, suffix string, opts WriteOptions) error {
+	source, info, err := resolveWriteTarget(filename, opts.Symlinks)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return err
+	}
+
+	backup, _, err := resolveWriteTarget(filename+suffix, opts.Symlinks)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(backup, content, info, opts.Sync)
+}
+
+// resolveWriteTarget returns the file that writing to filename replaces,
+// together with its current metadata, or nil if it does not exist yet.
+func resolveWriteTarget(filename string, policy SymlinkPolicy) (string, fs.FileInfo, error) {
+	info, err := os.Lstat(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return filename, nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	if info.Mode()&fs.ModeSymlink != 0 {
+		if policy == RefuseSymlinks {
+			return "", nil, fmt.Errorf("%w: %s", ErrSymlink, filename)
+		}
+		target, err := filepath.EvalSymlinks(filename)
+		if err != nil {
+			return "", nil, fmt.Errorf("following symbolic link %s: %w", filename, err)
+		}
+		filename = target
+		if info, err = os.Stat(target); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if !info.Mode().IsRegular() {
+		return "", nil, fmt.Errorf("%s is not a regular file", filename)
+	}
+	return filename, info, nil
+}
+
+// writeAtomic writes data to a temporary file next to filename and renames
+// it into place. The temporary file takes the mode and owner of original
+// if it is not nil.
+func writeAtomic(filename string, data []byte, original fs.FileInfo, sync bool) error {
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+
+	tmp, err := createTemp(dir, base)
+	if err != nil {
+		return err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if original != nil {
+		// Changing the owner clears the setuid and setgid bits, so the mode comes last
+		copyOwner(tmp, original)
+		if err := tmp.Chmod(original.Mode() & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky)); err != nil {
+			return err
+		}
+	}
+	if sync {
+		if err := tmp.Sync(); err != nil {
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return err
+	}
+	committed = true
+
+	if sync {
+		return syncDir(dir)
+	}
+	return nil
+}
+
+// createTemp creates a new hidden file for writing base in dir.
+// Unlike os.CreateTemp it applies the umask to mode 0644, so that new files
+// get the same permissions os.WriteFile would give them.
+func createTemp(dir, base string) (*os.File, error) {
+	for {
+		name := filepath.Join(dir, fmt.Sprintf(".%s.texted-%08x", base, rand.Uint32()))
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if !errors.Is(err, fs.ErrExist) {
+			return f, err
+		}
+	}
+}
+
+// syncDir flushes the directory entry of a renamed file.
+// Systems that cannot sync directories are not treated as failing.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil && !errors.Is(err, errors.ErrUnsupported) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package texted
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "script.sh")
+	if err := os.WriteFile(script, []byte("echo old\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link.sh")
+	if err := os.Symlink("script.sh", link); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("keeps mode", func(t *testing.T) {
+		if err := WriteFile(script, []byte("echo new\n"), WriteOptions{Sync: true}); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		info, err := os.Stat(script)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0755 {
+			t.Errorf("mode = %v, want 0755", info.Mode().Perm())
+		}
+	})
+
+	t.Run("follows symlinks", func(t *testing.T) {
+		if err := WriteFile(link, []byte("echo linked\n"), WriteOptions{}); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+			t.Errorf("link was replaced: %v, %v", info, err)
+		}
+		if content, _ := os.ReadFile(script); string(content) != "echo linked\n" {
+			t.Errorf("target content = %q", content)
+		}
+	})
+
+	t.Run("refuses symlinks", func(t *testing.T) {
+		err := WriteFile(link, []byte("echo refused\n"), WriteOptions{Symlinks: RefuseSymlinks})
+		if !errors.Is(err, ErrSymlink) {
+			t.Errorf("WriteFile() error = %v, want ErrSymlink", err)
+		}
+	})
+
+	t.Run("backup keeps mode", func(t *testing.T) {
+		if err := WriteBackup(script, ".bak", WriteOptions{}); err != nil {
+			t.Fatalf("WriteBackup() error = %v", err)
+		}
+		info, err := os.Stat(script + ".bak")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0755 {
+			t.Errorf("backup mode = %v, want 0755", info.Mode().Perm())
+		}
+	})
+
+	t.Run("leaves no temporary files", func(t *testing.T) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 3 {
+			t.Errorf("directory has %d entries, want 3", len(entries))
+		}
+	})
+}
+
+func TestWriteFile_KeepsSetuidAndSetgid(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no setuid and setgid bits on Windows")
+	}
+	filename := filepath.Join(t.TempDir(), "tool")
+	if err := os.WriteFile(filename, []byte("old\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	mode := 0755 | os.ModeSetuid | os.ModeSetgid
+	if err := os.Chmod(filename, mode); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFile(filename, []byte("new\n"), WriteOptions{}); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid); got != mode {
+		t.Errorf("mode = %v, want %v", got, mode)
+	}
+}
+
+func TestCommitEdits_RollsBack(t *testing.T) {
+	dir := t.TempDir()
+	written := filepath.Join(dir, "written.txt")
+	if err := os.WriteFile(written, []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	unwritable := filepath.Join(dir, "directory")
+	if err := os.Mkdir(unwritable, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := CommitEdits([]FileEdit{
+		{Filename: written, Original: "original", Modified: "modified"},
+		{Filename: unwritable, Modified: "modified"},
+	}, WriteOptions{})
+
+	var commitErr *CommitError
+	if !errors.As(err, &commitErr) || commitErr.Filename != unwritable {
+		t.Fatalf("CommitEdits() error = %v, want a CommitError for %s", err, unwritable)
+	}
+	if len(commitErr.Unrestored) != 0 {
+		t.Errorf("Unrestored = %v, want none", commitErr.Unrestored)
+	}
+	if content, _ := os.ReadFile(written); string(content) != "original" {
+		t.Errorf("written file = %q, want it restored", content)
+	}
+}